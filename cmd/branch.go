@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alajmo/mani/core"
+	"github.com/alajmo/mani/core/dao"
+	"github.com/alajmo/mani/core/exec"
+)
+
+func branchCmd(config *dao.Config, configErr *error) *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "branch",
+		Short: "Manage branches across projects",
+		Long: `Manage branches across projects.
+
+Create, checkout and delete the same branch in multiple projects. Projects with
+uncommitted changes are skipped unless --force is set.`,
+		Example: `  # Create branch feature in all projects
+  mani branch create feature --all
+
+  # Create branch and push it with upstream tracking
+  mani branch create feature --tags backend --push
+
+  # Checkout branch main in all projects
+  mani branch checkout main --all
+
+  # List current branch of all projects
+  mani branch list`,
+		DisableAutoGenTag: true,
+	}
+
+	cmd.AddCommand(
+		branchListCmd(config, configErr),
+		branchCreateCmd(config, configErr),
+		branchCheckoutCmd(config, configErr),
+		branchDeleteCmd(config, configErr),
+	)
+
+	return &cmd
+}
+
+// addBranchFlags adds the project selection flags and the flags shared by
+// the branch create, checkout and delete commands
+func addBranchFlags(cmd *cobra.Command, config *dao.Config, configErr *error, projectFlags *core.ProjectFlags, branchFlags *core.BranchFlags) {
+	cmd.Flags().StringVar(&branchFlags.Remote, "remote", "origin", "remote used when pushing")
+
+	cmd.Flags().BoolVarP(&projectFlags.All, "all", "a", false, "select all projects")
+	cmd.Flags().BoolVarP(&projectFlags.Cwd, "cwd", "k", false, "select current working directory")
+
+	cmd.Flags().StringSliceVarP(&projectFlags.Projects, "projects", "p", []string{}, "select projects by name")
+	err := cmd.RegisterFlagCompletionFunc("projects", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if *configErr != nil {
+			return []string{}, cobra.ShellCompDirectiveDefault
+		}
+
+		projects := config.GetProjectNames()
+		return projects, cobra.ShellCompDirectiveDefault
+	})
+	core.CheckIfError(err)
+
+	cmd.Flags().StringSliceVarP(&projectFlags.Paths, "paths", "d", []string{}, "select projects by path")
+	err = cmd.RegisterFlagCompletionFunc("paths", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if *configErr != nil {
+			return []string{}, cobra.ShellCompDirectiveDefault
+		}
+
+		options := config.GetProjectPaths()
+		return options, cobra.ShellCompDirectiveDefault
+	})
+	core.CheckIfError(err)
+
+	cmd.Flags().StringSliceVarP(&projectFlags.Tags, "tags", "t", []string{}, "select projects by tag")
+	err = cmd.RegisterFlagCompletionFunc("tags", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if *configErr != nil {
+			return []string{}, cobra.ShellCompDirectiveDefault
+		}
+
+		tags := config.GetTags()
+		return tags, cobra.ShellCompDirectiveDefault
+	})
+	core.CheckIfError(err)
+
+	cmd.Flags().StringVarP(&projectFlags.TagsExpr, "tags-expr", "E", "", "select projects by tags expression")
+
+	cmd.Flags().StringVarP(&projectFlags.Target, "target", "T", "", "select projects by target name")
+	err = cmd.RegisterFlagCompletionFunc("target", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if *configErr != nil {
+			return []string{}, cobra.ShellCompDirectiveDefault
+		}
+
+		values := config.GetTargetNames()
+		return values, cobra.ShellCompDirectiveDefault
+	})
+	core.CheckIfError(err)
+}
+
+type branchOperation func(projects []dao.Project, branch string, flags core.BranchFlags) []exec.BranchResult
+
+func runBranchOperation(
+	config *dao.Config,
+	branch string,
+	projectFlags *core.ProjectFlags,
+	branchFlags core.BranchFlags,
+	operation branchOperation,
+) {
+	projects, err := config.GetFilteredProjects(projectFlags)
+	core.CheckIfError(err)
+
+	if len(projects) == 0 {
+		core.Exit(&core.NoTargets{})
+	}
+
+	results := operation(projects, branch, branchFlags)
+
+	theme, err := config.GetTheme("default")
+	core.CheckIfError(err)
+	exec.PrintBranchResults(results, *theme, "table", []string{"project", "branch", "status", "message"})
+
+	if exec.HasBranchFailures(results) {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/alajmo/mani/core"
+	"github.com/alajmo/mani/core/dao"
+	"github.com/alajmo/mani/core/exec"
+)
+
+func branchCheckoutCmd(config *dao.Config, configErr *error) *cobra.Command {
+	var projectFlags core.ProjectFlags
+	var branchFlags core.BranchFlags
+
+	cmd := cobra.Command{
+		Aliases: []string{"switch"},
+		Use:     "checkout <branch>",
+		Short:   "Checkout branch in projects",
+		Long: `Checkout branch in projects.
+
+The branch is checked out from a local branch, or from a remote branch with
+the same name.`,
+		Example: `  # Checkout branch in all projects
+  mani branch checkout main --all
+
+  # Checkout branch in projects with tag backend
+  mani branch checkout feature --tags backend`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			core.CheckIfError(*configErr)
+			runBranchOperation(config, args[0], &projectFlags, branchFlags, exec.CheckoutBranch)
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return []string{}, cobra.ShellCompDirectiveNoFileComp
+		},
+		DisableAutoGenTag: true,
+	}
+
+	cmd.Flags().BoolVar(&branchFlags.Push, "push", false, "push branch and set upstream tracking")
+	cmd.Flags().BoolVarP(&branchFlags.Force, "force", "f", false, "ignore uncommitted changes")
+	addBranchFlags(&cmd, config, configErr, &projectFlags, &branchFlags)
+
+	return &cmd
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/alajmo/mani/core"
+	"github.com/alajmo/mani/core/dao"
+	"github.com/alajmo/mani/core/exec"
+)
+
+func branchCreateCmd(config *dao.Config, configErr *error) *cobra.Command {
+	var projectFlags core.ProjectFlags
+	var branchFlags core.BranchFlags
+
+	cmd := cobra.Command{
+		Use:   "create <branch>",
+		Short: "Create and checkout branch in projects",
+		Long: `Create and checkout branch in projects.
+
+Projects that already have the branch check it out instead.`,
+		Example: `  # Create branch in all projects
+  mani branch create feature --all
+
+  # Create branch and push it to origin with upstream tracking
+  mani branch create feature --projects <project> --push`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			core.CheckIfError(*configErr)
+			runBranchOperation(config, args[0], &projectFlags, branchFlags, exec.CreateBranch)
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return []string{}, cobra.ShellCompDirectiveNoFileComp
+		},
+		DisableAutoGenTag: true,
+	}
+
+	cmd.Flags().BoolVar(&branchFlags.Push, "push", false, "push branch and set upstream tracking")
+	cmd.Flags().BoolVarP(&branchFlags.Force, "force", "f", false, "ignore uncommitted changes")
+	addBranchFlags(&cmd, config, configErr, &projectFlags, &branchFlags)
+
+	return &cmd
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/alajmo/mani/core"
+	"github.com/alajmo/mani/core/dao"
+	"github.com/alajmo/mani/core/exec"
+)
+
+func branchDeleteCmd(config *dao.Config, configErr *error) *cobra.Command {
+	var projectFlags core.ProjectFlags
+	var branchFlags core.BranchFlags
+
+	cmd := cobra.Command{
+		Aliases: []string{"rm"},
+		Use:     "delete <branch>",
+		Short:   "Delete branch in projects",
+		Long: `Delete branch in projects.
+
+Unmerged branches are only deleted with --force. The branch cannot be deleted
+in projects where it is checked out.`,
+		Example: `  # Delete branch in all projects
+  mani branch delete feature --all
+
+  # Delete branch locally and on the remote
+  mani branch delete feature --all --push`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			core.CheckIfError(*configErr)
+			runBranchOperation(config, args[0], &projectFlags, branchFlags, exec.DeleteBranch)
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return []string{}, cobra.ShellCompDirectiveNoFileComp
+		},
+		DisableAutoGenTag: true,
+	}
+
+	cmd.Flags().BoolVar(&branchFlags.Push, "push", false, "delete branch on the remote")
+	cmd.Flags().BoolVarP(&branchFlags.Force, "force", "f", false, "delete unmerged branch")
+	addBranchFlags(&cmd, config, configErr, &projectFlags, &branchFlags)
+
+	return &cmd
+}
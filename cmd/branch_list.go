@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/alajmo/mani/core"
+	"github.com/alajmo/mani/core/dao"
+	"github.com/alajmo/mani/core/exec"
+)
+
+func branchListCmd(config *dao.Config, configErr *error) *cobra.Command {
+	var projectFlags core.ProjectFlags
+	var listFlags core.ListFlags
+
+	cmd := cobra.Command{
+		Aliases: []string{"ls"},
+		Use:     "list [projects]",
+		Short:   "List current branch of projects",
+		Long:    "List current branch of projects, along with uncommitted changes and upstream branch.",
+		Example: `  # List current branch of all projects
+  mani branch list
+
+  # List current branch of projects by tags
+  mani branch list --tags <tag>`,
+		Run: func(cmd *cobra.Command, args []string) {
+			core.CheckIfError(*configErr)
+
+			projectFlags.Projects = args
+			projectFlags.All = len(args) == 0 &&
+				len(projectFlags.Paths) == 0 &&
+				len(projectFlags.Tags) == 0 &&
+				projectFlags.TagsExpr == ""
+
+			projects, err := config.GetFilteredProjects(&projectFlags)
+			core.CheckIfError(err)
+
+			theme, err := config.GetTheme(listFlags.Theme)
+			core.CheckIfError(err)
+
+			results := exec.ListBranches(projects)
+			exec.PrintBranchResults(results, *theme, listFlags.Output, []string{"project", "branch", "status", "upstream"})
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if *configErr != nil {
+				return []string{}, cobra.ShellCompDirectiveDefault
+			}
+
+			return config.GetProjectNames(), cobra.ShellCompDirectiveNoFileComp
+		},
+		DisableAutoGenTag: true,
+	}
+
+	cmd.Flags().StringVar(&listFlags.Theme, "theme", "default", "set theme")
+	err := cmd.RegisterFlagCompletionFunc("theme", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if *configErr != nil {
+			return []string{}, cobra.ShellCompDirectiveDefault
+		}
+		names := config.GetThemeNames()
+		return names, cobra.ShellCompDirectiveDefault
+	})
+	core.CheckIfError(err)
+
+	cmd.Flags().StringVarP(&listFlags.Output, "output", "o", "table", "set output format [table|markdown|html]")
+	err = cmd.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if *configErr != nil {
+			return []string{}, cobra.ShellCompDirectiveDefault
+		}
+
+		valid := []string{"table", "markdown", "html"}
+		return valid, cobra.ShellCompDirectiveDefault
+	})
+	core.CheckIfError(err)
+
+	cmd.Flags().StringSliceVarP(&projectFlags.Tags, "tags", "t", []string{}, "select projects by tags")
+	err = cmd.RegisterFlagCompletionFunc("tags", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if *configErr != nil {
+			return []string{}, cobra.ShellCompDirectiveDefault
+		}
+
+		options := config.GetTags()
+		return options, cobra.ShellCompDirectiveDefault
+	})
+	core.CheckIfError(err)
+
+	cmd.Flags().StringVarP(&projectFlags.TagsExpr, "tags-expr", "E", "", "select projects by tags expression")
+
+	cmd.Flags().StringSliceVarP(&projectFlags.Paths, "paths", "d", []string{}, "select projects by paths")
+	err = cmd.RegisterFlagCompletionFunc("paths", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if *configErr != nil {
+			return []string{}, cobra.ShellCompDirectiveDefault
+		}
+
+		options := config.GetProjectPaths()
+		return options, cobra.ShellCompDirectiveDefault
+	})
+	core.CheckIfError(err)
+
+	return &cmd
+}
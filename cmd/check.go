@@ -1,31 +1,98 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
 
 	"github.com/spf13/cobra"
 
 	"github.com/alajmo/mani/core"
+	"github.com/alajmo/mani/core/dao"
 )
 
-func checkCmd(configErr *error) *cobra.Command {
+func checkCmd(config *dao.Config, configErr *error) *cobra.Command {
+	var strict bool
+	var output string
+
 	cmd := cobra.Command{
 		Use:   "check",
 		Short: "Validate config",
-		Long:  `Validate config.`,
+		Long: `Validate config.
+
+Besides errors, warns about deprecated fields and config which is valid
+but likely a mistake: unused specs, targets and themes, targets referencing
+tags no project has, tag expressions matching no project, projects sharing a
+path or url, missing project directories and shells not found in PATH.`,
 		Example: `  # Validate config
-  mani check`,
+  mani check
+
+  # Fail on warnings
+  mani check --strict
+
+  # Print errors and warnings as JSON
+  mani check --output json`,
 		Args: cobra.NoArgs,
 		Run: func(cmd *cobra.Command, args []string) {
-			if *configErr != nil {
-				fmt.Printf("Found configuration errors:\n\n")
-				core.Exit(*configErr)
-			}
-
-			fmt.Println("Config Valid")
+			runCheck(config, *configErr, strict, output)
 		},
 		DisableAutoGenTag: true,
 	}
 
+	cmd.Flags().BoolVar(&strict, "strict", false, "exit with error on warnings")
+	cmd.Flags().StringVarP(&output, "output", "o", "text", "set output format [text|json]")
+	err := cmd.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		valid := []string{"text", "json"}
+		return valid, cobra.ShellCompDirectiveDefault
+	})
+	core.CheckIfError(err)
+
 	return &cmd
 }
+
+func runCheck(config *dao.Config, configErr error, strict bool, output string) {
+	var lints []dao.Lint
+	if configErr == nil {
+		lints = config.Lint()
+	}
+	failed := configErr != nil || (strict && len(lints) > 0)
+
+	switch output {
+	case "json":
+		errors := []string{}
+		if configErr != nil {
+			errors = core.ErrorMessages(configErr)
+		}
+		if lints == nil {
+			lints = []dao.Lint{}
+		}
+
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+		err := encoder.Encode(struct {
+			Valid    bool       `json:"valid"`
+			Errors   []string   `json:"errors"`
+			Warnings []dao.Lint `json:"warnings"`
+		}{!failed, errors, lints})
+		core.CheckIfError(err)
+	case "text":
+		if configErr != nil {
+			fmt.Printf("Found configuration errors:\n\n")
+			core.Exit(configErr)
+		}
+
+		if len(lints) > 0 {
+			fmt.Print(dao.FormatLints(lints))
+			fmt.Printf("Config valid with %d warnings\n", len(lints))
+		} else {
+			fmt.Println("Config Valid")
+		}
+	default:
+		core.CheckIfError(&core.CheckOutputInvalid{Output: output})
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
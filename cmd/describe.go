@@ -13,19 +13,23 @@ func describeCmd(config *dao.Config, configErr *error) *cobra.Command {
 	cmd := cobra.Command{
 		Aliases: []string{"desc"},
 		Use:     "describe",
-		Short:   "Describe projects and tasks",
-		Long:    "Describe projects and tasks.",
+		Short:   "Describe projects, tasks and config",
+		Long:    "Describe projects, tasks and config.",
 		Example: `  # Describe all projects
   mani describe projects
 
   # Describe all tasks
-  mani describe tasks`,
+  mani describe tasks
+
+  # Describe config with a profile applied
+  mani describe config --profile ci`,
 		DisableAutoGenTag: true,
 	}
 
 	cmd.AddCommand(
 		describeProjectsCmd(config, configErr, &describeFlags),
 		describeTasksCmd(config, configErr, &describeFlags),
+		describeConfigCmd(config, configErr),
 	)
 
 	cmd.PersistentFlags().StringVar(&describeFlags.Theme, "theme", "default", "set theme")
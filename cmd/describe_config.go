@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alajmo/mani/core"
+	"github.com/alajmo/mani/core/dao"
+)
+
+func describeConfigCmd(config *dao.Config, configErr *error) *cobra.Command {
+	cmd := cobra.Command{
+		Aliases: []string{"cfg"},
+		Use:     "config",
+		Short:   "Describe config",
+		Long:    "Describe the effective config, with the selected profile applied.",
+		Example: `  # Describe config
+  mani describe config
+
+  # Describe config with profile ci applied
+  mani describe config --profile ci`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			core.CheckIfError(*configErr)
+
+			output, err := config.DescribeConfig()
+			core.CheckIfError(err)
+			fmt.Print(output)
+		},
+		DisableAutoGenTag: true,
+	}
+
+	return &cmd
+}
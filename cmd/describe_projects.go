@@ -139,6 +139,9 @@ func describeProjects(
 			theme, err := config.GetTheme(describeFlags.Theme)
 			core.CheckIfError(err)
 
+			err = dao.ParseProjectsEnv(projects)
+			core.CheckIfError(err)
+
 			output := print.PrintProjectBlocks(projects, true, theme.Block, print.GookitFormatter{})
 			fmt.Print(output)
 		}
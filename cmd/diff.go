@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alajmo/mani/core"
+	"github.com/alajmo/mani/core/dao"
+	"github.com/alajmo/mani/core/exec"
+)
+
+func diffCmd(config *dao.Config, configErr *error) *cobra.Command {
+	var projectFlags core.ProjectFlags
+	var diffFlags core.DiffFlags
+
+	cmd := cobra.Command{
+		Use:   "diff [projects]",
+		Short: "Compare config with the repositories on disk",
+		Long: `Compare config with the repositories on disk.
+
+Lists projects that are not cloned, git repositories that are not declared in
+the config, remote and branch mismatches, and worktrees that differ from the
+config. Undeclared repositories are only reported when no projects are selected.`,
+		Example: `  # Compare all projects
+  mani diff
+
+  # Compare projects by tags
+  mani diff --tags <tag>
+
+  # Print a YAML patch that brings the config up to date with the disk
+  mani diff --patch`,
+		Run: func(cmd *cobra.Command, args []string) {
+			core.CheckIfError(*configErr)
+			runDiff(config, args, projectFlags, diffFlags)
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if *configErr != nil {
+				return []string{}, cobra.ShellCompDirectiveDefault
+			}
+
+			projectNames := config.GetProjectNames()
+			return projectNames, cobra.ShellCompDirectiveNoFileComp
+		},
+		DisableAutoGenTag: true,
+	}
+
+	cmd.Flags().BoolVar(&diffFlags.Patch, "patch", false, "print a YAML patch to bring the config up to date")
+
+	cmd.Flags().StringSliceVarP(&projectFlags.Tags, "tags", "t", []string{}, "select projects by tags")
+	err := cmd.RegisterFlagCompletionFunc("tags", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if *configErr != nil {
+			return []string{}, cobra.ShellCompDirectiveDefault
+		}
+
+		options := config.GetTags()
+		return options, cobra.ShellCompDirectiveDefault
+	})
+	core.CheckIfError(err)
+
+	cmd.Flags().StringVarP(&projectFlags.TagsExpr, "tags-expr", "E", "", "select projects by tags expression")
+
+	cmd.Flags().StringSliceVarP(&projectFlags.Paths, "paths", "d", []string{}, "select projects by paths")
+	err = cmd.RegisterFlagCompletionFunc("paths", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if *configErr != nil {
+			return []string{}, cobra.ShellCompDirectiveDefault
+		}
+
+		options := config.GetProjectPaths()
+		return options, cobra.ShellCompDirectiveDefault
+	})
+	core.CheckIfError(err)
+
+	return &cmd
+}
+
+func runDiff(
+	config *dao.Config,
+	args []string,
+	projectFlags core.ProjectFlags,
+	diffFlags core.DiffFlags,
+) {
+	// If no flag is set for targetting projects, then assume all projects
+	var allProjects bool
+	if len(args) == 0 &&
+		projectFlags.TagsExpr == "" &&
+		len(projectFlags.Paths) == 0 &&
+		len(projectFlags.Tags) == 0 {
+		allProjects = true
+	}
+
+	projects, err := config.FilterProjects(false, allProjects, args, projectFlags.Paths, projectFlags.Tags, projectFlags.TagsExpr)
+	core.CheckIfError(err)
+
+	diffs, err := exec.DiffProjects(config, projects, allProjects)
+	core.CheckIfError(err)
+
+	if diffFlags.Patch {
+		patch, err := exec.DiffPatch(config, diffs)
+		core.CheckIfError(err)
+		fmt.Print(patch)
+		return
+	}
+
+	exec.PrintProjectDiffs(diffs)
+}
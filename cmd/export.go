@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alajmo/mani/core"
+	"github.com/alajmo/mani/core/dao"
+)
+
+func exportCmd(config *dao.Config, configErr *error) *cobra.Command {
+	var projectFlags core.ProjectFlags
+	var setProjectFlags core.SetProjectFlags
+	var exportFlags core.ExportFlags
+
+	cmd := cobra.Command{
+		Use:   "export [projects]",
+		Short: "Export projects to other manifest formats",
+		Long: `Export projects to other manifest formats.
+
+Supported formats:
+  repos     vcstool repositories file
+  repo-xml  Google repo XML manifest, a remote is added for each host
+  json      list of projects
+  yaml      projects section of a mani config
+  csv       one row per project
+
+Projects without url are omitted from the repos and repo-xml formats, since
+they cannot be cloned by other tools. Remotes and worktrees are only included
+in the json, yaml and csv formats.`,
+		Example: `  # Export all projects to a vcstool file
+  mani export --format repos > workspace.repos
+
+  # Export projects by tags to a repo manifest
+  mani export --format repo-xml --tags backend > default.xml
+
+  # Export the projects of a target to a json file
+  mani export --format json --target backend > projects.json`,
+		Run: func(cmd *cobra.Command, args []string) {
+			core.CheckIfError(*configErr)
+
+			setProjectFlags.All = cmd.Flags().Changed("all")
+			setProjectFlags.Cwd = cmd.Flags().Changed("cwd")
+			setProjectFlags.Target = cmd.Flags().Changed("target")
+
+			runExport(config, args, &projectFlags, &setProjectFlags, exportFlags)
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if *configErr != nil {
+				return []string{}, cobra.ShellCompDirectiveDefault
+			}
+
+			projectNames := config.GetProjectNames()
+			return projectNames, cobra.ShellCompDirectiveNoFileComp
+		},
+		DisableAutoGenTag: true,
+	}
+
+	cmd.Flags().StringVarP(&exportFlags.Format, "format", "f", "yaml", "set export format [repos|repo-xml|json|yaml|csv]")
+	err := cmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return dao.EXPORT_FORMATS, cobra.ShellCompDirectiveDefault
+	})
+	core.CheckIfError(err)
+
+	cmd.Flags().BoolVarP(&projectFlags.All, "all", "a", true, "select all projects")
+	cmd.Flags().BoolVarP(&projectFlags.Cwd, "cwd", "k", false, "select current working directory")
+
+	cmd.Flags().StringSliceVarP(&projectFlags.Tags, "tags", "t", []string{}, "select projects by tags")
+	err = cmd.RegisterFlagCompletionFunc("tags", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if *configErr != nil {
+			return []string{}, cobra.ShellCompDirectiveDefault
+		}
+
+		options := config.GetTags()
+		return options, cobra.ShellCompDirectiveDefault
+	})
+	core.CheckIfError(err)
+
+	cmd.Flags().StringVarP(&projectFlags.TagsExpr, "tags-expr", "E", "", "select projects by tags expression")
+
+	cmd.Flags().StringSliceVarP(&projectFlags.Paths, "paths", "d", []string{}, "select projects by paths")
+	err = cmd.RegisterFlagCompletionFunc("paths", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if *configErr != nil {
+			return []string{}, cobra.ShellCompDirectiveDefault
+		}
+
+		options := config.GetProjectPaths()
+		return options, cobra.ShellCompDirectiveDefault
+	})
+	core.CheckIfError(err)
+
+	cmd.Flags().StringVarP(&projectFlags.Target, "target", "T", "", "select projects by target name")
+	err = cmd.RegisterFlagCompletionFunc("target", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if *configErr != nil {
+			return []string{}, cobra.ShellCompDirectiveDefault
+		}
+
+		values := config.GetTargetNames()
+		return values, cobra.ShellCompDirectiveDefault
+	})
+	core.CheckIfError(err)
+
+	return &cmd
+}
+
+func runExport(
+	config *dao.Config,
+	args []string,
+	projectFlags *core.ProjectFlags,
+	setProjectFlags *core.SetProjectFlags,
+	exportFlags core.ExportFlags,
+) {
+	projectFlags.Projects = args
+	// If flag All is not set and no other filters are applied set All to true.
+	if !setProjectFlags.All {
+		isNoFiltersSet := len(projectFlags.Projects) == 0 &&
+			len(projectFlags.Paths) == 0 &&
+			len(projectFlags.Tags) == 0 &&
+			projectFlags.TagsExpr == "" &&
+			!setProjectFlags.Cwd &&
+			!setProjectFlags.Target
+		projectFlags.All = isNoFiltersSet
+	}
+	projects, err := config.GetFilteredProjects(projectFlags)
+	core.CheckIfError(err)
+
+	out, err := dao.ExportProjects(exportFlags.Format, projects)
+	core.CheckIfError(err)
+
+	fmt.Print(out)
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alajmo/mani/core"
+	"github.com/alajmo/mani/core/dao"
+)
+
+func fmtCmd(config *dao.Config, configErr *error) *cobra.Command {
+	var check bool
+	var sortProjects bool
+
+	cmd := cobra.Command{
+		Use:   "fmt",
+		Short: "Format config",
+		Long: `Format config.
+
+Rewrites the config and its local imports in canonical form: 2 space
+indentation and keys in the order of the documented config. Comments
+are kept and entries are not moved in front of the anchors they
+reference. Remote imports and the user config are not formatted.`,
+		Example: `  # Format config
+  mani fmt
+
+  # Sort projects by name
+  mani fmt --sort
+
+  # List unformatted files and exit with error, for use in a pre-commit hook
+  mani fmt --check`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			core.CheckIfError(*configErr)
+			runFmt(config, check, sortProjects)
+		},
+		DisableAutoGenTag: true,
+	}
+
+	cmd.Flags().BoolVar(&check, "check", false, "list unformatted files and exit with error instead of writing them")
+	cmd.Flags().BoolVar(&sortProjects, "sort", false, "sort projects by name")
+
+	return &cmd
+}
+
+func runFmt(config *dao.Config, check bool, sortProjects bool) {
+	var unformatted []string
+	for _, path := range config.ConfigFiles() {
+		f, err := dao.ReadYAMLFile(path)
+		core.CheckIfError(err)
+
+		src, err := os.ReadFile(path)
+		core.CheckIfError(err)
+
+		out, err := f.Format(sortProjects)
+		core.CheckIfError(err)
+
+		if bytes.Equal(src, out) {
+			continue
+		}
+		unformatted = append(unformatted, path)
+
+		if !check {
+			info, err := os.Stat(path)
+			core.CheckIfError(err)
+			err = os.WriteFile(path, out, info.Mode())
+			core.CheckIfError(err)
+		}
+
+		if rel, err := filepath.Rel(config.Dir, path); err == nil {
+			path = rel
+		}
+		fmt.Println(path)
+	}
+
+	if check && len(unformatted) > 0 {
+		os.Exit(1)
+	}
+}
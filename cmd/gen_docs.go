@@ -27,11 +27,23 @@ func genDocsCmd(longAppDesc string) *cobra.Command {
 				execCmd(&config, &configErr),
 				initCmd(),
 				syncCmd(&config, &configErr),
+				diffCmd(&config, &configErr),
+				worktreeCmd(&config, &configErr),
+				branchCmd(&config, &configErr),
+				importManifestCmd(&config, &configErr),
+				exportCmd(&config, &configErr),
+				importCmd(&config, &configErr),
 				editCmd(&config, &configErr),
+				projectCmd(&config, &configErr),
+				taskCmd(&config, &configErr),
+				tagCmd(&config, &configErr),
 				listCmd(&config, &configErr),
 				describeCmd(&config, &configErr),
 				tuiCmd(&config, &configErr),
-				checkCmd(&configErr),
+				checkCmd(&config, &configErr),
+				fmtCmd(&config, &configErr),
+				migrateCmd(&config, &configErr),
+				schemaCmd(),
 				genCmd(),
 			)
 			core.CheckIfError(err)
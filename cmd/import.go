@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/alajmo/mani/core/dao"
+)
+
+func importCmd(config *dao.Config, configErr *error) *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "import",
+		Short: "Manage config imports",
+		Long: `Manage config imports.
+
+Imports can be local paths or remote urls, remote imports are fetched into a
+local cache the first time they are used:
+
+  # Config in a git repository, pinned to a branch, tag or commit
+  git+https://github.com/org/shared-tasks.git//tasks.yaml?ref=v2
+
+  # Config in an archive (.tar.gz, .tgz or .zip)
+  https://example.com/shared-tasks.tar.gz//tasks.yaml
+  file:///opt/shared-tasks.zip//tasks.yaml
+
+  # Single config file
+  https://example.com/tasks.yaml
+
+If no path is given after //, mani looks for a mani.yaml in the root of the
+repository or archive. Cached imports are only refreshed with mani import update.`,
+		Example: `  # Refresh the cache of all remote imports
+  mani import update`,
+		DisableAutoGenTag: true,
+	}
+
+	cmd.AddCommand(
+		importUpdateCmd(config, configErr),
+	)
+
+	return &cmd
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alajmo/mani/core"
+	"github.com/alajmo/mani/core/dao"
+	"github.com/alajmo/mani/core/exec"
+)
+
+func importManifestCmd(config *dao.Config, configErr *error) *cobra.Command {
+	var importFlags core.ImportManifestFlags
+
+	cmd := cobra.Command{
+		Use:   "import-manifest <file>",
+		Short: "Import projects from manifests of other multi-repo tools",
+		Long: `Import projects from manifests of other multi-repo tools.
+
+Supported formats:
+  repo     Google repo XML manifest (default.xml)
+  vcstool  vcstool repositories file (.repos)
+  mr       myrepos config (.mrconfig)
+  gita     gita repositories file (repos.csv), groups are read from groups.csv in the same directory
+  meta     meta config (.meta)
+
+Project name, path, url, branch and groups (as tags) are added to the projects
+of the mani config. Projects with a path that is already declared are skipped.
+Relative project paths are resolved from the manifest directory, or for repo
+manifests in a .repo directory, from the directory containing .repo.
+The format is detected from the file name if --format is not set.`,
+		Example: `  # Import projects from a repo manifest
+  mani import-manifest --format repo .repo/manifests/default.xml
+
+  # Import projects from a vcstool file
+  mani import-manifest ros2.repos`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			core.CheckIfError(*configErr)
+
+			format := importFlags.Format
+			if format == "" {
+				var err error
+				format, err = dao.GetManifestFormat(args[0])
+				core.CheckIfError(err)
+			}
+
+			path, err := filepath.Abs(args[0])
+			core.CheckIfError(err)
+
+			projects, err := dao.ParseManifest(format, path, config.Dir)
+			core.CheckIfError(err)
+
+			added, skipped, err := config.ImportProjects(projects)
+			core.CheckIfError(err)
+
+			exec.PrintProjectImport(added, skipped)
+		},
+		DisableAutoGenTag: true,
+	}
+
+	cmd.Flags().StringVarP(&importFlags.Format, "format", "f", "", "set manifest format [repo|vcstool|mr|gita|meta]")
+	err := cmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return dao.MANIFEST_FORMATS, cobra.ShellCompDirectiveDefault
+	})
+	core.CheckIfError(err)
+
+	return &cmd
+}
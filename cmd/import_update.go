@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alajmo/mani/core"
+	"github.com/alajmo/mani/core/dao"
+	"github.com/alajmo/mani/core/exec"
+)
+
+func importUpdateCmd(config *dao.Config, configErr *error) *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "update",
+		Short: "Refresh the cache of remote imports",
+		Long: `Refresh the cache of remote imports.
+
+Fetches all remote imports, including imports of remote configs. If an import
+cannot be fetched, for instance when offline, the cached copy is kept.`,
+		Example: `  # Refresh the cache of all remote imports
+  mani import update`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			// Imports that failed to resolve are fetched again, so only fail if
+			// no imports were found
+			if len(config.ImportData) == 0 {
+				core.CheckIfError(*configErr)
+			}
+
+			statuses := runImportUpdate(config)
+			exec.PrintImportStatus(statuses)
+
+			for _, status := range statuses {
+				if status.Status == exec.ImportFailed {
+					os.Exit(1)
+				}
+			}
+		},
+		DisableAutoGenTag: true,
+	}
+
+	return &cmd
+}
+
+// runImportUpdate updates the remote imports, and reads the config again to
+// find remote imports of updated configs
+func runImportUpdate(config *dao.Config) []exec.ImportStatus {
+	var statuses []exec.ImportStatus
+	done := make(map[string]bool)
+	imports := config.ImportData
+
+	for {
+		updated := exec.UpdateImports(imports, done)
+		if len(updated) == 0 {
+			return statuses
+		}
+		statuses = append(statuses, updated...)
+
+		c, _ := dao.ReadConfig(configFilepath, userConfigPath, profile, color)
+		imports = c.ImportData
+	}
+}
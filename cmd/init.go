@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"fmt"
+
 	"github.com/spf13/cobra"
 
 	"github.com/alajmo/mani/core"
@@ -17,7 +19,10 @@ func initCmd() *cobra.Command {
 		Long: `Initialize a mani repository.
 
 Creates a new mani repository by generating a mani.yaml configuration file 
-and a .gitignore file in the current directory.`,
+and a .gitignore file in the current directory.
+
+Use --update in an existing mani repository to add newly discovered
+repositories and worktrees to the config, keeping comments and ordering.`,
 
 		Example: `  # Initialize with default settings
   mani init
@@ -26,10 +31,18 @@ and a .gitignore file in the current directory.`,
   mani init --auto-discovery=false
 
   # Initialize without updating .gitignore
-  mani init --sync-gitignore=false`,
+  mani init --sync-gitignore=false
+
+  # Add newly discovered repositories to an existing mani.yaml
+  mani init --update`,
 
 		Args: cobra.MaximumNArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
+			if initFlags.Update {
+				runInitUpdate(args, initFlags)
+				return
+			}
+
 			foundProjects, err := dao.InitMani(args, initFlags)
 			core.CheckIfError(err)
 
@@ -42,6 +55,24 @@ and a .gitignore file in the current directory.`,
 
 	cmd.Flags().BoolVar(&initFlags.AutoDiscovery, "auto-discovery", true, "automatically discover and add Git repositories to mani.yaml")
 	cmd.Flags().BoolVarP(&initFlags.SyncGitignore, "sync-gitignore", "g", true, "synchronize .gitignore file")
+	cmd.Flags().BoolVar(&initFlags.Update, "update", false, "add newly discovered repositories to an existing mani.yaml")
 
 	return &cmd
 }
+
+func runInitUpdate(args []string, initFlags core.InitFlags) {
+	config, projects, err := dao.UpdateMani(args)
+	core.CheckIfError(err)
+
+	if len(projects) == 0 {
+		fmt.Println("No new projects or worktrees found")
+		return
+	}
+
+	if initFlags.SyncGitignore && *config.SyncGitignore {
+		err = exec.UpdateGitignoreIfExists(&config)
+		core.CheckIfError(err)
+	}
+
+	exec.PrintProjectUpdate(projects)
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alajmo/mani/core"
+	"github.com/alajmo/mani/core/dao"
+)
+
+func migrateCmd(config *dao.Config, configErr *error) *cobra.Command {
+	var dryRun bool
+
+	cmd := cobra.Command{
+		Use:   "migrate",
+		Short: "Migrate config to the current version",
+		Long: `Migrate config to the current version.
+
+Renames deprecated fields in the config and its local imports and sets
+the config version. The changes are printed as a diff. Remote imports
+and the user config are not migrated.`,
+		Example: `  # Migrate config
+  mani migrate
+
+  # Print the changes without writing them
+  mani migrate --dry-run`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			core.CheckIfError(*configErr)
+			runMigrate(config, dryRun)
+		},
+		DisableAutoGenTag: true,
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "print the changes without writing them")
+
+	return &cmd
+}
+
+func runMigrate(config *dao.Config, dryRun bool) {
+	changed := 0
+	for _, path := range config.ConfigFiles() {
+		f, err := dao.ReadYAMLFile(path)
+		core.CheckIfError(err)
+
+		src, err := os.ReadFile(path)
+		core.CheckIfError(err)
+
+		f.Migrate()
+		out, err := f.Marshal()
+		core.CheckIfError(err)
+
+		name := path
+		if rel, err := filepath.Rel(config.Dir, path); err == nil {
+			name = rel
+		}
+
+		diff := dao.FormatDiff(name, src, out)
+		if diff == "" {
+			continue
+		}
+		changed++
+		fmt.Print(diff)
+
+		if !dryRun {
+			err = f.Write()
+			core.CheckIfError(err)
+		}
+	}
+
+	if changed == 0 {
+		fmt.Printf("Config is up to date (version %d)\n", dao.CONFIG_VERSION)
+	} else if !dryRun {
+		fmt.Printf("\nMigrated %d files to version %d\n", changed, dao.CONFIG_VERSION)
+	}
+}
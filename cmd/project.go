@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/alajmo/mani/core/dao"
+)
+
+func projectCmd(config *dao.Config, configErr *error) *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "project",
+		Short: "Add, remove and modify projects in config",
+		Long: `Add, remove and modify projects in config.
+
+New projects are added to the main config file, existing projects are modified
+in the config file where they are declared. Comments and formatting of the
+config files are kept.`,
+		Example: `  # Add project
+  mani project add <project> --url <url>
+
+  # Set field of project
+  mani project set <project> desc "Backend API"
+
+  # Remove project
+  mani project remove <project>`,
+		DisableAutoGenTag: true,
+	}
+
+	cmd.AddCommand(
+		projectAddCmd(config, configErr),
+		projectRemoveCmd(config, configErr),
+		projectSetCmd(config, configErr),
+	)
+
+	return &cmd
+}
+
+func completeProjectNames(config *dao.Config, configErr *error) func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if *configErr != nil || len(args) > 0 {
+			return []string{}, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		return config.GetProjectNames(), cobra.ShellCompDirectiveNoFileComp
+	}
+}
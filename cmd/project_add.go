@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alajmo/mani/core"
+	"github.com/alajmo/mani/core/dao"
+)
+
+func projectAddCmd(config *dao.Config, configErr *error) *cobra.Command {
+	var projectFlags core.AddProjectFlags
+
+	cmd := cobra.Command{
+		Use:   "add <project>",
+		Short: "Add project to config",
+		Long: `Add project to config.
+
+The project is added to the main config file. The path is relative to the
+config directory and defaults to the project name.`,
+		Example: `  # Add project in directory api
+  mani project add api --url git@github.com:org/api.git
+
+  # Add project with path and tags
+  mani project add web --path frontend/web --tag frontend --tag node`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			core.CheckIfError(*configErr)
+
+			project := dao.Project{
+				Name:   args[0],
+				Path:   projectFlags.Path,
+				URL:    projectFlags.URL,
+				Desc:   projectFlags.Desc,
+				Branch: projectFlags.Branch,
+				Tags:   projectFlags.Tags,
+			}
+			err := config.AddProject(project)
+			core.CheckIfError(err)
+
+			fmt.Printf("Added project `%s`\n", project.Name)
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return []string{}, cobra.ShellCompDirectiveNoFileComp
+		},
+		DisableAutoGenTag: true,
+	}
+
+	cmd.Flags().StringVarP(&projectFlags.Path, "path", "p", "", "path of project, relative to the config directory")
+	err := cmd.RegisterFlagCompletionFunc("path", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return nil, cobra.ShellCompDirectiveFilterDirs
+	})
+	core.CheckIfError(err)
+	cmd.Flags().StringVar(&projectFlags.URL, "url", "", "git url of project")
+	cmd.Flags().StringVar(&projectFlags.Desc, "desc", "", "description of project")
+	cmd.Flags().StringVarP(&projectFlags.Branch, "branch", "b", "", "branch to clone")
+	cmd.Flags().StringSliceVarP(&projectFlags.Tags, "tag", "t", []string{}, "tags of project")
+
+	return &cmd
+}
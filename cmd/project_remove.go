@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alajmo/mani/core"
+	"github.com/alajmo/mani/core/dao"
+)
+
+func projectRemoveCmd(config *dao.Config, configErr *error) *cobra.Command {
+	cmd := cobra.Command{
+		Aliases: []string{"rm"},
+		Use:     "remove <project>",
+		Short:   "Remove project from config",
+		Long: `Remove project from config.
+
+The project is removed from the config file where it is declared, the project
+directory is kept.`,
+		Example: `  # Remove project
+  mani project remove <project>`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			core.CheckIfError(*configErr)
+
+			err := config.RemoveProject(args[0])
+			core.CheckIfError(err)
+
+			fmt.Printf("Removed project `%s`\n", args[0])
+		},
+		ValidArgsFunction: completeProjectNames(config, configErr),
+		DisableAutoGenTag: true,
+	}
+
+	return &cmd
+}
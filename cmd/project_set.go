@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alajmo/mani/core"
+	"github.com/alajmo/mani/core/dao"
+)
+
+func projectSetCmd(config *dao.Config, configErr *error) *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "set <project> <field> <value>",
+		Short: "Set field of project",
+		Long: `Set field of project.
+
+The field is set in the config file where the project is declared, an empty
+value removes the field. Available fields are path, desc, url, clone, branch,
+single_branch and sync.`,
+		Example: `  # Set description of project
+  mani project set <project> desc "Backend API"
+
+  # Exclude project from sync
+  mani project set <project> sync false
+
+  # Remove branch of project
+  mani project set <project> branch ""`,
+		Args: cobra.ExactArgs(3),
+		Run: func(cmd *cobra.Command, args []string) {
+			core.CheckIfError(*configErr)
+
+			err := config.SetProjectField(args[0], args[1], args[2])
+			core.CheckIfError(err)
+
+			fmt.Printf("Set `%s` of project `%s`\n", args[1], args[0])
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if *configErr != nil {
+				return []string{}, cobra.ShellCompDirectiveDefault
+			}
+
+			switch len(args) {
+			case 0:
+				return config.GetProjectNames(), cobra.ShellCompDirectiveNoFileComp
+			case 1:
+				return []string{"path", "desc", "url", "clone", "branch", "single_branch", "sync"}, cobra.ShellCompDirectiveNoFileComp
+			default:
+				return []string{}, cobra.ShellCompDirectiveNoFileComp
+			}
+		},
+		DisableAutoGenTag: true,
+	}
+
+	return &cmd
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/alajmo/mani/core"
 	"github.com/alajmo/mani/core/dao"
 )
 
@@ -20,6 +21,7 @@ var (
 	configErr      error
 	configFilepath string
 	userConfigPath string
+	profile        string
 	color          bool
 	buildMode      = ""
 	version        = "dev"
@@ -29,6 +31,9 @@ var (
 		Use:     appName,
 		Short:   shortAppDesc,
 		Version: version,
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			printDeprecations(cmd)
+		},
 	}
 )
 
@@ -51,6 +56,14 @@ func init() {
 	rootCmd.PersistentFlags().StringVarP(&configFilepath, "config", "c", "", "specify config")
 	rootCmd.PersistentFlags().StringVarP(&userConfigPath, "user-config", "u", "", "specify user config")
 	rootCmd.PersistentFlags().BoolVar(&color, "color", true, "enable color")
+	rootCmd.PersistentFlags().StringVar(&profile, "profile", "", "select config profile")
+	err := rootCmd.RegisterFlagCompletionFunc("profile", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if configErr != nil {
+			return []string{}, cobra.ShellCompDirectiveDefault
+		}
+		return config.GetProfileNames(), cobra.ShellCompDirectiveNoFileComp
+	})
+	core.CheckIfError(err)
 
 	rootCmd.AddCommand(
 		completionCmd(),
@@ -61,8 +74,20 @@ func init() {
 		listCmd(&config, &configErr),
 		describeCmd(&config, &configErr),
 		syncCmd(&config, &configErr),
+		diffCmd(&config, &configErr),
+		worktreeCmd(&config, &configErr),
+		branchCmd(&config, &configErr),
+		importManifestCmd(&config, &configErr),
+		exportCmd(&config, &configErr),
+		importCmd(&config, &configErr),
 		editCmd(&config, &configErr),
-		checkCmd(&configErr),
+		projectCmd(&config, &configErr),
+		taskCmd(&config, &configErr),
+		tagCmd(&config, &configErr),
+		checkCmd(&config, &configErr),
+		fmtCmd(&config, &configErr),
+		migrateCmd(&config, &configErr),
+		schemaCmd(),
 		tuiCmd(&config, &configErr),
 	)
 
@@ -83,5 +108,19 @@ Issues:        https://github.com/alajmo/mani/issues
 }
 
 func initConfig() {
-	config, configErr = dao.ReadConfig(configFilepath, userConfigPath, color)
+	config, configErr = dao.ReadConfig(configFilepath, userConfigPath, profile, color)
+}
+
+// printDeprecations warns about renamed fields in the config, except for the
+// commands reporting them themselves and shell completion
+func printDeprecations(cmd *cobra.Command) {
+	switch cmd.Name() {
+	case "check", "migrate", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+		return
+	}
+
+	if len(config.Deprecations) > 0 {
+		fmt.Fprint(os.Stderr, dao.FormatLints(config.Deprecations))
+		fmt.Fprintf(os.Stderr, "Run `mani migrate` to update the config\n\n")
+	}
 }
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alajmo/mani/core"
+	"github.com/alajmo/mani/core/dao"
+)
+
+func schemaCmd() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "schema",
+		Short: "Print JSON Schema of config",
+		Long: `Print the JSON Schema of the mani config.
+
+The schema can be used by editors to validate and complete mani.yaml, for
+instance with yaml-language-server by adding a modeline to the config:
+
+  # yaml-language-server: $schema=mani.schema.json`,
+		Example: `  # Save the schema
+  mani schema > mani.schema.json`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			out, err := json.MarshalIndent(dao.GenerateSchema(), "", "  ")
+			core.CheckIfError(err)
+			fmt.Println(string(out))
+		},
+		DisableAutoGenTag: true,
+	}
+
+	return &cmd
+}
@@ -36,7 +36,22 @@ credentials for each repository individually.`,
   mani sync --ignore-sync-state
 
   # Display sync status
-  mani sync --status`,
+  mani sync --status
+
+  # Display what sync would do, without cloning or modifying anything
+  mani sync --dry-run
+
+  # List git repositories no longer declared in the config
+  mani sync --prune --dry-run
+
+  # Remove git repositories no longer declared in the config, after confirming
+  mani sync --prune
+
+  # Remove git repositories no longer declared in the config without confirming
+  mani sync --prune --yes
+
+  # Move git repositories no longer declared in the config to an archive directory
+  mani sync --prune --prune-archive .archive`,
 		Run: func(cmd *cobra.Command, args []string) {
 			core.CheckIfError(*configErr)
 
@@ -76,6 +91,10 @@ credentials for each repository individually.`,
 	cmd.Flags().BoolVar(&syncFlags.IgnoreSyncState, "ignore-sync-state", false, "sync project even if the project's sync field is set to false")
 	cmd.Flags().BoolVarP(&syncFlags.Parallel, "parallel", "p", false, "clone projects in parallel")
 	cmd.Flags().BoolVarP(&syncFlags.Status, "status", "s", false, "display status only")
+	cmd.Flags().BoolVar(&syncFlags.Prune, "prune", false, "remove git repositories not declared in config")
+	cmd.Flags().StringVar(&syncFlags.PruneArchive, "prune-archive", "", "move pruned repositories to this directory instead of removing them")
+	cmd.Flags().BoolVar(&syncFlags.DryRun, "dry-run", false, "display what would be synced and pruned without modifying anything")
+	cmd.Flags().BoolVarP(&syncFlags.Yes, "yes", "y", false, "prune without asking for confirmation")
 	cmd.Flags().Uint32P("forks", "f", 4, "maximum number of concurrent processes")
 
 	// Targets
@@ -139,7 +158,14 @@ func runSync(
 		if setSyncFlags.SyncGitignore {
 			config.SyncGitignore = &syncFlags.SyncGitignore
 		}
+	}
 
+	if !syncFlags.Status && syncFlags.DryRun {
+		err = exec.PrintSyncPlan(config, projects, syncFlags)
+		core.CheckIfError(err)
+	}
+
+	if !syncFlags.Status && !syncFlags.DryRun {
 		if *config.SyncGitignore {
 			err := exec.UpdateGitignoreIfExists(config)
 			core.CheckIfError(err)
@@ -149,6 +175,11 @@ func runSync(
 		core.CheckIfError(err)
 	}
 
+	if !syncFlags.Status && syncFlags.Prune {
+		err = exec.PruneProjects(config, syncFlags.PruneArchive, syncFlags.DryRun, syncFlags.Yes)
+		core.CheckIfError(err)
+	}
+
 	err = exec.PrintProjectStatus(config, projects)
 	core.CheckIfError(err)
 }
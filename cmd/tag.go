@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/alajmo/mani/core/dao"
+)
+
+func tagCmd(config *dao.Config, configErr *error) *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "tag",
+		Short: "Add and remove tags of projects in config",
+		Long: `Add and remove tags of projects in config.
+
+Tags are modified in the config file where the project is declared. Comments
+and formatting of the config files are kept.`,
+		Example: `  # Add tag to project
+  mani tag add <project> <tag>
+
+  # Remove tag from project
+  mani tag remove <project> <tag>`,
+		DisableAutoGenTag: true,
+	}
+
+	cmd.AddCommand(
+		tagAddCmd(config, configErr),
+		tagRemoveCmd(config, configErr),
+	)
+
+	return &cmd
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alajmo/mani/core"
+	"github.com/alajmo/mani/core/dao"
+)
+
+func tagAddCmd(config *dao.Config, configErr *error) *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "add <project> <tag>",
+		Short: "Add tag to project",
+		Long:  `Add tag to project.`,
+		Example: `  # Add tag frontend to project
+  mani tag add <project> frontend`,
+		Args: cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			core.CheckIfError(*configErr)
+
+			err := config.AddProjectTag(args[0], args[1])
+			core.CheckIfError(err)
+
+			fmt.Printf("Added tag `%s` to project `%s`\n", args[1], args[0])
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if *configErr != nil {
+				return []string{}, cobra.ShellCompDirectiveDefault
+			}
+
+			switch len(args) {
+			case 0:
+				return config.GetProjectNames(), cobra.ShellCompDirectiveNoFileComp
+			case 1:
+				return config.GetTags(), cobra.ShellCompDirectiveNoFileComp
+			default:
+				return []string{}, cobra.ShellCompDirectiveNoFileComp
+			}
+		},
+		DisableAutoGenTag: true,
+	}
+
+	return &cmd
+}
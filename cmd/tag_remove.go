@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alajmo/mani/core"
+	"github.com/alajmo/mani/core/dao"
+)
+
+func tagRemoveCmd(config *dao.Config, configErr *error) *cobra.Command {
+	cmd := cobra.Command{
+		Aliases: []string{"rm"},
+		Use:     "remove <project> <tag>",
+		Short:   "Remove tag from project",
+		Long:    `Remove tag from project.`,
+		Example: `  # Remove tag frontend from project
+  mani tag remove <project> frontend`,
+		Args: cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			core.CheckIfError(*configErr)
+
+			err := config.RemoveProjectTag(args[0], args[1])
+			core.CheckIfError(err)
+
+			fmt.Printf("Removed tag `%s` from project `%s`\n", args[1], args[0])
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if *configErr != nil {
+				return []string{}, cobra.ShellCompDirectiveDefault
+			}
+
+			switch len(args) {
+			case 0:
+				return config.GetProjectNames(), cobra.ShellCompDirectiveNoFileComp
+			case 1:
+				project, err := config.GetProject(args[0])
+				if err != nil {
+					return []string{}, cobra.ShellCompDirectiveNoFileComp
+				}
+				return project.Tags, cobra.ShellCompDirectiveNoFileComp
+			default:
+				return []string{}, cobra.ShellCompDirectiveNoFileComp
+			}
+		},
+		DisableAutoGenTag: true,
+	}
+
+	return &cmd
+}
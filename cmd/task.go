@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/alajmo/mani/core/dao"
+)
+
+func taskCmd(config *dao.Config, configErr *error) *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "task",
+		Short: "Add and remove tasks in config",
+		Long: `Add and remove tasks in config.
+
+New tasks are added to the main config file, tasks are removed from the config
+file where they are declared. Comments and formatting of the config files are
+kept.`,
+		Example: `  # Add task
+  mani task add <task> "git status"
+
+  # Remove task
+  mani task remove <task>`,
+		DisableAutoGenTag: true,
+	}
+
+	cmd.AddCommand(
+		taskAddCmd(config, configErr),
+		taskRemoveCmd(config, configErr),
+	)
+
+	return &cmd
+}
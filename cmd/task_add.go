@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alajmo/mani/core"
+	"github.com/alajmo/mani/core/dao"
+)
+
+func taskAddCmd(config *dao.Config, configErr *error) *cobra.Command {
+	var taskFlags core.AddTaskFlags
+
+	cmd := cobra.Command{
+		Use:   "add <task> <command>",
+		Short: "Add task to config",
+		Long:  `Add task to config.`,
+		Example: `  # Add task
+  mani task add status "git status"
+
+  # Add task with description
+  mani task add pull "git pull --rebase" --desc "Pull latest changes"`,
+		Args: cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			core.CheckIfError(*configErr)
+
+			err := config.AddTask(args[0], args[1], taskFlags.Desc)
+			core.CheckIfError(err)
+
+			fmt.Printf("Added task `%s`\n", args[0])
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return []string{}, cobra.ShellCompDirectiveNoFileComp
+		},
+		DisableAutoGenTag: true,
+	}
+
+	cmd.Flags().StringVar(&taskFlags.Desc, "desc", "", "description of task")
+
+	return &cmd
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alajmo/mani/core"
+	"github.com/alajmo/mani/core/dao"
+)
+
+func taskRemoveCmd(config *dao.Config, configErr *error) *cobra.Command {
+	cmd := cobra.Command{
+		Aliases: []string{"rm"},
+		Use:     "remove <task>",
+		Short:   "Remove task from config",
+		Long: `Remove task from config.
+
+The task is removed from the config file where it is declared.`,
+		Example: `  # Remove task
+  mani task remove <task>`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			core.CheckIfError(*configErr)
+
+			err := config.RemoveTask(args[0])
+			core.CheckIfError(err)
+
+			fmt.Printf("Removed task `%s`\n", args[0])
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if *configErr != nil || len(args) > 0 {
+				return []string{}, cobra.ShellCompDirectiveNoFileComp
+			}
+
+			return config.GetTaskNames(), cobra.ShellCompDirectiveNoFileComp
+		},
+		DisableAutoGenTag: true,
+	}
+
+	return &cmd
+}
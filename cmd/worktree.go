@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/alajmo/mani/core/dao"
+)
+
+func worktreeCmd(config *dao.Config, configErr *error) *cobra.Command {
+	cmd := cobra.Command{
+		Aliases: []string{"wt"},
+		Use:     "worktree",
+		Short:   "Manage git worktrees of projects",
+		Long: `Manage git worktrees of projects.
+
+Worktrees added or removed are also added to or removed from the project's
+worktrees in the config file where the project is declared.`,
+		Example: `  # List worktrees of all projects
+  mani worktree list
+
+  # Add worktree for project <project> on branch <branch>
+  mani worktree add <project> <path> --branch <branch>
+
+  # Remove worktree from project <project>
+  mani worktree remove <project> <path>`,
+		DisableAutoGenTag: true,
+	}
+
+	cmd.AddCommand(
+		worktreeListCmd(config, configErr),
+		worktreeAddCmd(config, configErr),
+		worktreeRemoveCmd(config, configErr),
+	)
+
+	return &cmd
+}
+
+func completeProjectWorktrees(config *dao.Config, configErr *error) func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if *configErr != nil {
+			return []string{}, cobra.ShellCompDirectiveDefault
+		}
+
+		if len(args) == 0 {
+			return config.GetProjectNames(), cobra.ShellCompDirectiveNoFileComp
+		}
+
+		if len(args) == 1 {
+			project, err := config.GetProject(args[0])
+			if err != nil {
+				return []string{}, cobra.ShellCompDirectiveNoFileComp
+			}
+
+			paths := []string{}
+			for _, wt := range project.WorktreeList {
+				paths = append(paths, wt.Path)
+			}
+			return paths, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		return []string{}, cobra.ShellCompDirectiveNoFileComp
+	}
+}
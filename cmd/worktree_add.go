@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alajmo/mani/core"
+	"github.com/alajmo/mani/core/dao"
+	"github.com/alajmo/mani/core/exec"
+)
+
+func worktreeAddCmd(config *dao.Config, configErr *error) *cobra.Command {
+	var worktreeFlags core.WorktreeFlags
+
+	cmd := cobra.Command{
+		Use:   "add <project> <path>",
+		Short: "Add worktree to project",
+		Long: `Add worktree to project.
+
+The path is relative to the project directory. An existing branch is checked
+out, otherwise a new branch is created. The branch defaults to the basename
+of the path.`,
+		Example: `  # Add worktree in <project>/feature on branch feature
+  mani worktree add <project> feature
+
+  # Add worktree on a specific branch
+  mani worktree add <project> ../project-hotfix --branch hotfix/login`,
+		Args: cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			core.CheckIfError(*configErr)
+
+			project, err := config.GetProject(args[0])
+			core.CheckIfError(err)
+
+			wt := dao.Worktree{Path: args[1], Branch: worktreeFlags.Branch}
+			err = exec.AddWorktree(config, *project, wt)
+			core.CheckIfError(err)
+
+			fmt.Printf("Added worktree `%s` to project `%s`\n", args[1], project.Name)
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if *configErr != nil {
+				return []string{}, cobra.ShellCompDirectiveDefault
+			}
+
+			if len(args) == 0 {
+				return config.GetProjectNames(), cobra.ShellCompDirectiveNoFileComp
+			}
+
+			return []string{}, cobra.ShellCompDirectiveDefault
+		},
+		DisableAutoGenTag: true,
+	}
+
+	cmd.Flags().StringVarP(&worktreeFlags.Branch, "branch", "b", "", "branch to check out, created if it does not exist")
+
+	return &cmd
+}
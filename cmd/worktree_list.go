@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alajmo/mani/core"
+	"github.com/alajmo/mani/core/dao"
+	"github.com/alajmo/mani/core/exec"
+)
+
+func worktreeListCmd(config *dao.Config, configErr *error) *cobra.Command {
+	var projectFlags core.ProjectFlags
+	var listFlags core.ListFlags
+
+	cmd := cobra.Command{
+		Aliases: []string{"ls"},
+		Use:     "list [projects]",
+		Short:   "List worktrees",
+		Long: `List worktrees.
+
+Lists worktrees declared in the config and worktrees found on disk, along
+with their branch and whether they have uncommitted changes.`,
+		Example: `  # List worktrees of all projects
+  mani worktree list
+
+  # List worktrees of projects by tags
+  mani worktree list --tags <tag>`,
+		Run: func(cmd *cobra.Command, args []string) {
+			core.CheckIfError(*configErr)
+
+			projectFlags.Projects = args
+			projectFlags.All = len(args) == 0 &&
+				len(projectFlags.Paths) == 0 &&
+				len(projectFlags.Tags) == 0 &&
+				projectFlags.TagsExpr == ""
+
+			projects, err := config.GetFilteredProjects(&projectFlags)
+			core.CheckIfError(err)
+
+			statuses, err := exec.ListWorktrees(projects)
+			core.CheckIfError(err)
+
+			if len(statuses) == 0 {
+				fmt.Println("No worktrees")
+				return
+			}
+
+			theme, err := config.GetTheme(listFlags.Theme)
+			core.CheckIfError(err)
+
+			exec.PrintWorktrees(statuses, *theme, listFlags.Output)
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if *configErr != nil {
+				return []string{}, cobra.ShellCompDirectiveDefault
+			}
+
+			return config.GetProjectNames(), cobra.ShellCompDirectiveNoFileComp
+		},
+		DisableAutoGenTag: true,
+	}
+
+	cmd.Flags().StringVar(&listFlags.Theme, "theme", "default", "set theme")
+	err := cmd.RegisterFlagCompletionFunc("theme", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if *configErr != nil {
+			return []string{}, cobra.ShellCompDirectiveDefault
+		}
+		names := config.GetThemeNames()
+		return names, cobra.ShellCompDirectiveDefault
+	})
+	core.CheckIfError(err)
+
+	cmd.Flags().StringVarP(&listFlags.Output, "output", "o", "table", "set output format [table|markdown|html]")
+	err = cmd.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if *configErr != nil {
+			return []string{}, cobra.ShellCompDirectiveDefault
+		}
+
+		valid := []string{"table", "markdown", "html"}
+		return valid, cobra.ShellCompDirectiveDefault
+	})
+	core.CheckIfError(err)
+
+	cmd.Flags().StringSliceVarP(&projectFlags.Tags, "tags", "t", []string{}, "select projects by tags")
+	err = cmd.RegisterFlagCompletionFunc("tags", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if *configErr != nil {
+			return []string{}, cobra.ShellCompDirectiveDefault
+		}
+
+		options := config.GetTags()
+		return options, cobra.ShellCompDirectiveDefault
+	})
+	core.CheckIfError(err)
+
+	cmd.Flags().StringVarP(&projectFlags.TagsExpr, "tags-expr", "E", "", "select projects by tags expression")
+
+	cmd.Flags().StringSliceVarP(&projectFlags.Paths, "paths", "d", []string{}, "select projects by paths")
+	err = cmd.RegisterFlagCompletionFunc("paths", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if *configErr != nil {
+			return []string{}, cobra.ShellCompDirectiveDefault
+		}
+
+		options := config.GetProjectPaths()
+		return options, cobra.ShellCompDirectiveDefault
+	})
+	core.CheckIfError(err)
+
+	return &cmd
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alajmo/mani/core"
+	"github.com/alajmo/mani/core/dao"
+	"github.com/alajmo/mani/core/exec"
+)
+
+func worktreeRemoveCmd(config *dao.Config, configErr *error) *cobra.Command {
+	var worktreeFlags core.WorktreeFlags
+
+	cmd := cobra.Command{
+		Aliases: []string{"rm"},
+		Use:     "remove <project> <path>",
+		Short:   "Remove worktree from project",
+		Long: `Remove worktree from project.
+
+The worktree directory is removed, the branch is kept.`,
+		Example: `  # Remove worktree
+  mani worktree remove <project> feature
+
+  # Remove worktree even if it has uncommitted changes
+  mani worktree remove <project> feature --force`,
+		Args: cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			core.CheckIfError(*configErr)
+
+			project, err := config.GetProject(args[0])
+			core.CheckIfError(err)
+
+			err = exec.DeleteWorktree(config, *project, args[1], worktreeFlags.Force)
+			core.CheckIfError(err)
+
+			fmt.Printf("Removed worktree `%s` from project `%s`\n", args[1], project.Name)
+		},
+		ValidArgsFunction: completeProjectWorktrees(config, configErr),
+		DisableAutoGenTag: true,
+	}
+
+	cmd.Flags().BoolVarP(&worktreeFlags.Force, "force", "f", false, "remove worktree with uncommitted changes")
+
+	return &cmd
+}
@@ -5,7 +5,9 @@ import (
 	"os"
 	"os/exec"
 	"regexp"
+	"runtime"
 	"strings"
+	"time"
 
 	"github.com/gookit/color"
 	"gopkg.in/yaml.v3"
@@ -58,43 +60,87 @@ func FormatErrors(re Resource, errs []error) error {
 
 // ENV
 
+// ParseNodeEnv returns the env in the form [key=value]. Values are either
+// scalars or mappings with value and cache, see ParseEnvCache for the cache
+// durations.
 func ParseNodeEnv(node yaml.Node) []string {
 	var envs []string
 	count := len(node.Content)
 
 	for i := 0; i < count; i += 2 {
-		env := fmt.Sprintf("%v=%v", node.Content[i].Value, node.Content[i+1].Value)
+		value := node.Content[i+1].Value
+		if node.Content[i+1].Kind == yaml.MappingNode {
+			var entry struct {
+				Value string `yaml:"value"`
+			}
+			_ = node.Content[i+1].Decode(&entry)
+			value = entry.Value
+		}
+
+		env := fmt.Sprintf("%v=%v", node.Content[i].Value, value)
 		envs = append(envs, env)
 	}
 
 	return envs
 }
 
-func EvaluateEnv(envList []string) ([]string, error) {
-	var envs []string
+// EvaluateEnv evaluates values in the form $(command) in parallel, at most
+// one per CPU at a time. Values with a cache duration are read from the env
+// cache while they're not expired.
+func EvaluateEnv(envList []string, cache EnvCache) ([]string, error) {
+	envs := make([]string, len(envList))
+	errs := make([]error, len(envList))
 
-	for _, arg := range envList {
+	wg := core.NewSizedWaitGroup(uint32(runtime.NumCPU()))
+	for i, arg := range envList {
 		kv := strings.SplitN(arg, "=", 2)
+		envs[i] = fmt.Sprintf("%v=%v", kv[0], kv[1])
 
 		if val, hasPrefix := strings.CutPrefix(kv[1], "$("); hasPrefix {
 			if cmdStr, hasSuffix := strings.CutSuffix(val, ")"); hasSuffix {
-				cmd := exec.Command("sh", "-c", cmdStr)
-				cmd.Env = os.Environ()
-				out, err := cmd.CombinedOutput()
-				if err != nil {
-					return envs, &core.ConfigEnvFailed{Name: kv[0], Err: string(out)}
-				}
-
-				envs = append(envs, fmt.Sprintf("%v=%v", kv[0], string(out)))
-				continue
+				wg.Add()
+				go func() {
+					defer wg.Done()
+					out, err := evaluateEnvCmd(kv[0], cmdStr, cache[kv[0]])
+					envs[i] = fmt.Sprintf("%v=%v", kv[0], out)
+					errs[i] = err
+				}()
 			}
 		}
-		envs = append(envs, fmt.Sprintf("%v=%v", kv[0], kv[1]))
+	}
+
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return envs[:i], err
+		}
 	}
 
 	return envs, nil
 }
 
+func evaluateEnvCmd(name string, cmdStr string, ttl time.Duration) (string, error) {
+	if ttl > 0 {
+		if out, ok := readEnvCache(name, cmdStr, ttl); ok {
+			return out, nil
+		}
+	}
+
+	cmd := exec.Command("sh", "-c", cmdStr)
+	cmd.Env = os.Environ()
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", &core.ConfigEnvFailed{Name: name, Err: string(out)}
+	}
+
+	if ttl > 0 {
+		writeEnvCache(cmdStr, string(out))
+	}
+
+	return string(out), nil
+}
+
 // MergeEnvs Merges environment variables.
 // Priority is from highest to lowest (1st env takes precedence over the last entry).
 func MergeEnvs(envs ...[]string) []string {
@@ -5,8 +5,10 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 	"text/template"
+	"time"
 
 	"github.com/alajmo/mani/core"
 	"github.com/gookit/color"
@@ -16,7 +18,7 @@ import (
 var (
 	DEFAULT_SHELL         = "bash -c"
 	DEFAULT_SHELL_PROGRAM = "bash"
-	ACCEPTABLE_FILE_NAMES = []string{"mani.yaml", "mani.yml", ".mani.yaml", ".mani.yml"}
+	ACCEPTABLE_FILE_NAMES = []string{"mani.yaml", "mani.yml", ".mani.yaml", ".mani.yml", "mani.toml", "mani.json"}
 
 	DEFAULT_THEME = Theme{
 		Name:   "default",
@@ -61,32 +63,50 @@ var (
 type Config struct {
 	// Internal
 	EnvList        []string  `yaml:"-"`
+	EnvCache       EnvCache  `yaml:"-"`
+	EnvFileList    []EnvFile `yaml:"-"`
 	ImportData     []Import  `yaml:"-"`
 	ThemeList      []Theme   `yaml:"-"`
 	SpecList       []Spec    `yaml:"-"`
 	TargetList     []Target  `yaml:"-"`
 	ProjectList    []Project `yaml:"-"`
 	TaskList       []Task    `yaml:"-"`
+	ProfileList    []Profile `yaml:"-"`
+	SecretList     []Secret  `yaml:"-"`
+	Profile        string    `yaml:"-"` // Active profile
 	Path           string    `yaml:"-"`
 	Dir            string    `yaml:"-"`
 	UserConfigFile *string   `yaml:"-"`
 	ConfigPaths    []string  `yaml:"-"`
 	Color          bool      `yaml:"-"`
+	Deprecations   []Lint    `yaml:"-"` // Renamed fields found while reading the config
 
+	interpolationEnv   []string  // env used for ${VAR} while loading the config, defaults to EnvList
+	interpolationCache EnvCache  // cache durations of interpolationEnv
+	projectTemplates   yaml.Node // project templates of all loaded configs
+
+	Version                 int    `yaml:"version"`
 	Shell                   string `yaml:"shell"`
 	SyncRemotes             *bool  `yaml:"sync_remotes"`
 	SyncGitignore           *bool  `yaml:"sync_gitignore"`
 	RemoveOrphanedWorktrees *bool  `yaml:"remove_orphaned_worktrees"`
 	ReloadTUI               *bool  `yaml:"reload_tui_on_change"`
 
+	ProjectTasks ProjectTasks `yaml:"project_tasks"`
+
 	// Intermediate
 	Env      yaml.Node `yaml:"env"`
+	EnvFiles yaml.Node `yaml:"env_files"`
 	Import   yaml.Node `yaml:"import"`
 	Themes   yaml.Node `yaml:"themes"`
 	Specs    yaml.Node `yaml:"specs"`
 	Targets  yaml.Node `yaml:"targets"`
 	Projects yaml.Node `yaml:"projects"`
 	Tasks    yaml.Node `yaml:"tasks"`
+	Profiles yaml.Node `yaml:"profiles"`
+	Secrets  yaml.Node `yaml:"secrets"`
+
+	ProjectTemplates yaml.Node `yaml:"project_templates"`
 }
 
 func (c *Config) GetContext() string {
@@ -99,14 +119,7 @@ func (c *Config) GetContextLine() int {
 
 // Returns the config env list as a string splice in the form [key=value, key1=$(echo 123)]
 func (c Config) GetEnvList() []string {
-	var envs []string
-	count := len(c.Env.Content)
-	for i := 0; i < count; i += 2 {
-		env := fmt.Sprintf("%v=%v", c.Env.Content[i].Value, c.Env.Content[i+1].Value)
-		envs = append(envs, env)
-	}
-
-	return envs
+	return ParseNodeEnv(c.Env)
 }
 
 func getUserConfigFile(userConfigPath string) *string {
@@ -134,7 +147,7 @@ func getUserConfigFile(userConfigPath string) *string {
 }
 
 // Function to read Mani configs.
-func ReadConfig(configFilepath string, userConfigPath string, colorFlag bool) (Config, error) {
+func ReadConfig(configFilepath string, userConfigPath string, profile string, colorFlag bool) (Config, error) {
 	color := CheckUserColor(colorFlag)
 	var configPath string
 
@@ -193,7 +206,7 @@ func ReadConfig(configFilepath string, userConfigPath string, colorFlag bool) (C
 	config.UserConfigFile = userConfigFile
 	config.Color = color
 
-	err = yaml.Unmarshal(dat, &config)
+	config.Deprecations, err = decodeConfig(dat, &config)
 	if err != nil {
 		re := ResourceErrors[Config]{Resource: &config, Errors: []error{err}}
 		return config, FormatErrors(re.Resource, re.Errors)
@@ -226,7 +239,32 @@ func ReadConfig(configFilepath string, userConfigPath string, colorFlag bool) (C
 		config.RemoveOrphanedWorktrees = core.Ptr(false)
 	}
 
-	configResources, err := config.importConfigs()
+	// Profiles are read from the main config only. The profile is looked up
+	// before importing, so its env is used in ${VAR} of projects and imports
+	profiles, profileErrors := config.GetProfileList()
+	config.ProfileList = profiles
+	var profileErr = ""
+	for _, profileError := range profileErrors {
+		profileErr = fmt.Sprintf("%s%s", profileErr, FormatErrors(profileError.Resource, profileError.Errors))
+	}
+	if profileErr != "" {
+		return config, &core.ConfigErr{Msg: profileErr}
+	}
+
+	profile = getProfileName(profile)
+	var activeProfile *Profile
+	if profile != "" {
+		activeProfile, err = config.GetProfile(profile)
+		if err != nil {
+			return config, err
+		}
+	}
+
+	configResources, err := config.importConfigs(activeProfile)
+	// Set before checking the error, so remote imports can be updated even if
+	// some of them fail to resolve
+	config.ImportData = configResources.Imports
+	config.Deprecations = append(config.Deprecations, configResources.Deprecations...)
 	if err != nil {
 		return config, err
 	}
@@ -237,6 +275,8 @@ func ReadConfig(configFilepath string, userConfigPath string, colorFlag bool) (C
 	config.SpecList = configResources.Specs
 	config.TargetList = configResources.Targets
 	config.EnvList = configResources.Envs
+	config.EnvCache = configResources.EnvCache
+	config.EnvFileList = configResources.EnvFiles
 
 	config.CheckConfigNoColor()
 
@@ -262,6 +302,34 @@ func ReadConfig(configFilepath string, userConfigPath string, colorFlag bool) (C
 		config.TargetList = append(config.TargetList, DEFAULT_TARGET)
 	}
 
+	// Secrets are read from the main config only, and resolved when a task runs
+	secrets, secretErrors := config.GetSecretList()
+	config.SecretList = secrets
+	var secretErr = ""
+	for _, secretError := range secretErrors {
+		secretErr = fmt.Sprintf("%s%s", secretErr, FormatErrors(secretError.Resource, secretError.Errors))
+	}
+	if secretErr != "" {
+		return config, &core.ConfigErr{Msg: secretErr}
+	}
+
+	// Apply profile before parsing tasks, so tasks reference the overlaid specs and targets
+	if profile != "" {
+		err = config.ApplyProfile(profile)
+		if err != nil {
+			return config, err
+		}
+	}
+
+	// Resolve extends, before tasks and commands reference the extended tasks
+	var configErr = ""
+	for _, taskError := range config.extendTasks() {
+		configErr = fmt.Sprintf("%s%s", configErr, FormatErrors(taskError.Resource, taskError.Errors))
+	}
+	if configErr != "" {
+		return config, &core.ConfigErr{Msg: configErr}
+	}
+
 	// Parse all tasks
 	taskErrors := make([]ResourceErrors[Task], len(configResources.Tasks))
 	for i := range configResources.Tasks {
@@ -269,13 +337,17 @@ func ReadConfig(configFilepath string, userConfigPath string, colorFlag bool) (C
 		configResources.Tasks[i].ParseTask(config, &taskErrors[i])
 	}
 
-	var configErr = ""
 	for _, taskError := range taskErrors {
 		if len(taskError.Errors) > 0 {
 			configErr = fmt.Sprintf("%s%s", configErr, FormatErrors(taskError.Resource, taskError.Errors))
 		}
 	}
 
+	// Parse project task overrides, after the tasks they override
+	for _, taskError := range config.parseProjectTasks() {
+		configErr = fmt.Sprintf("%s%s", configErr, FormatErrors(taskError.Resource, taskError.Errors))
+	}
+
 	if configErr != "" {
 		return config, &core.ConfigErr{Msg: configErr}
 	}
@@ -283,6 +355,124 @@ func ReadConfig(configFilepath string, userConfigPath string, colorFlag bool) (C
 	return config, nil
 }
 
+// DescribeConfig returns the effective config as YAML, including imported
+// resources and the overlays of the active profile
+func (c Config) DescribeConfig() (string, error) {
+	settings := struct {
+		Profile                 string       `yaml:"profile,omitempty"`
+		Shell                   string       `yaml:"shell"`
+		SyncRemotes             *bool        `yaml:"sync_remotes"`
+		SyncGitignore           *bool        `yaml:"sync_gitignore"`
+		RemoveOrphanedWorktrees *bool        `yaml:"remove_orphaned_worktrees"`
+		ReloadTUI               *bool        `yaml:"reload_tui_on_change"`
+		ProjectTasks            ProjectTasks `yaml:"project_tasks,omitempty"`
+	}{c.Profile, c.Shell, c.SyncRemotes, c.SyncGitignore, c.RemoveOrphanedWorktrees, c.ReloadTUI, c.ProjectTasks}
+
+	root := &yaml.Node{}
+	err := root.Encode(settings)
+	if err != nil {
+		return "", err
+	}
+
+	if len(c.EnvList) > 0 {
+		root.Content = append(root.Content, ScalarNode("env"), envNode(c.EnvList))
+	}
+	if len(c.EnvFileList) > 0 {
+		root.Content = append(root.Content, ScalarNode("env_files"), envFilesNode(c.EnvFileList))
+	}
+	if len(c.SecretList) > 0 {
+		secrets, err := secretsNode(c.SecretList)
+		if err != nil {
+			return "", err
+		}
+		root.Content = append(root.Content, ScalarNode("secrets"), secrets)
+	}
+
+	specs := &yaml.Node{Kind: yaml.MappingNode}
+	for _, spec := range c.SpecList {
+		node := &yaml.Node{}
+		err := node.Encode(spec)
+		if err != nil {
+			return "", err
+		}
+		RemoveMappingKey(node, "name")
+		specs.Content = append(specs.Content, ScalarNode(spec.Name), node)
+	}
+	root.Content = append(root.Content, ScalarNode("specs"), specs)
+
+	targets := &yaml.Node{Kind: yaml.MappingNode}
+	for _, target := range c.TargetList {
+		node := &yaml.Node{}
+		err := node.Encode(target)
+		if err != nil {
+			return "", err
+		}
+		RemoveMappingKey(node, "name")
+		targets.Content = append(targets.Content, ScalarNode(target.Name), node)
+	}
+	root.Content = append(root.Content, ScalarNode("targets"), targets)
+
+	projectList := slices.Clone(c.ProjectList)
+	err = ParseProjectsEnv(projectList)
+	if err != nil {
+		return "", err
+	}
+
+	projects := &yaml.Node{Kind: yaml.MappingNode}
+	for _, p := range projectList {
+		p.Path = p.RelPath
+		node := ProjectNode(p)
+		if node.Kind != yaml.MappingNode && (len(p.EnvList) > 0 || len(p.EnvFileList) > 0) {
+			node = &yaml.Node{Kind: yaml.MappingNode}
+		}
+		if len(p.EnvList) > 0 {
+			node.Content = append(node.Content, ScalarNode("env"), envNode(p.EnvList))
+		}
+		if len(p.EnvFileList) > 0 {
+			node.Content = append(node.Content, ScalarNode("env_files"), envFilesNode(p.EnvFileList))
+		}
+		projects.Content = append(projects.Content, ScalarNode(p.Name), node)
+	}
+	root.Content = append(root.Content, ScalarNode("projects"), projects)
+
+	return EncodeYAML(root)
+}
+
+func envNode(envs []string) *yaml.Node {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for _, env := range envs {
+		kv := strings.SplitN(strings.TrimSuffix(env, "\n"), "=", 2)
+		value := ""
+		if len(kv) == 2 {
+			value = kv[1]
+		}
+		valueNode := ScalarNode(Redact(value))
+		if cachedAt, ok := EnvCachedAt(env); ok {
+			valueNode.LineComment = "cached at " + cachedAt.Format(time.RFC3339)
+		}
+		node.Content = append(node.Content, ScalarNode(kv[0]), valueNode)
+	}
+
+	return node
+}
+
+// envFilesNode maps the path of each env file to the variables it declares
+func envFilesNode(envFiles []EnvFile) *yaml.Node {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for _, envFile := range envFiles {
+		keys := &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+		for _, key := range envFile.Keys() {
+			keys.Content = append(keys.Content, ScalarNode(key))
+		}
+		if !envFile.Found {
+			keys.LineComment = "not found"
+		}
+		node.Content = append(node.Content, ScalarNode(envFile.Path), keys)
+	}
+
+	return node
+}
+
 // Open mani config in editor
 func (c Config) EditConfig() error {
 	return openEditor(c.Path, -1)
@@ -344,6 +534,7 @@ func (c Config) EditTask(name string) error {
 			return err
 		}
 		configPath = task.context
+		name = unqualifyName(task.Namespace, task.Name)
 	}
 
 	dat, err := os.ReadFile(configPath)
@@ -356,7 +547,7 @@ func (c Config) EditTask(name string) error {
 	}
 
 	var configTmp ConfigTmp
-	err = yaml.Unmarshal([]byte(dat), &configTmp)
+	err = decodeConfigFile(configPath, dat, &configTmp)
 	if err != nil {
 		return err
 	}
@@ -385,6 +576,7 @@ func (c Config) EditProject(name string) error {
 			return err
 		}
 		configPath = project.context
+		name = unqualifyName(project.Namespace, project.Name)
 	}
 
 	dat, err := os.ReadFile(configPath)
@@ -397,7 +589,7 @@ func (c Config) EditProject(name string) error {
 	}
 
 	var configTmp ConfigTmp
-	err = yaml.Unmarshal([]byte(dat), &configTmp)
+	err = decodeConfigFile(configPath, dat, &configTmp)
 	if err != nil {
 		return err
 	}
@@ -417,32 +609,37 @@ func (c Config) EditProject(name string) error {
 	return openEditor(configPath, lineNr)
 }
 
-func InitMani(args []string, initFlags core.InitFlags) ([]Project, error) {
-	// Choose to initialize mani in a different directory
-	// 1. absolute or
-	// 2. relative or
-	// 3. working directory
-	var configDir string
+// Choose to initialize mani in a different directory
+// 1. absolute or
+// 2. relative or
+// 3. working directory
+func getInitDir(args []string) (string, error) {
 	if len(args) > 0 && filepath.IsAbs(args[0]) {
 		// absolute path
-		configDir = args[0]
-	} else if len(args) > 0 {
+		return args[0], nil
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+
+	if len(args) > 0 {
 		// relative path
-		wd, err := os.Getwd()
-		if err != nil {
-			return []Project{}, err
-		}
-		configDir = filepath.Join(wd, args[0])
-	} else {
-		// working directory
-		wd, err := os.Getwd()
-		if err != nil {
-			return []Project{}, err
-		}
-		configDir = wd
+		return filepath.Join(wd, args[0]), nil
 	}
 
-	err := os.MkdirAll(configDir, os.ModePerm)
+	// working directory
+	return wd, nil
+}
+
+func InitMani(args []string, initFlags core.InitFlags) ([]Project, error) {
+	configDir, err := getInitDir(args)
+	if err != nil {
+		return []Project{}, err
+	}
+
+	err = os.MkdirAll(configDir, os.ModePerm)
 	if err != nil {
 		return []Project{}, err
 	}
@@ -603,6 +800,127 @@ tasks:
 	return projects, nil
 }
 
+// UpdateMani discovers git repositories and worktrees below an existing mani
+// directory and appends the ones missing from the config. New projects are written
+// to the main config file, new worktrees to the file where the project is declared.
+// Returns the added projects, and existing projects with only the added worktrees.
+func UpdateMani(args []string) (Config, []Project, error) {
+	configDir, err := getInitDir(args)
+	if err != nil {
+		return Config{}, []Project{}, err
+	}
+
+	configPath, err := core.FindFileInParentDirs(configDir, ACCEPTABLE_FILE_NAMES)
+	if err != nil || filepath.Dir(configPath) != configDir {
+		return Config{}, []Project{}, &core.ConfigNotFound{Names: ACCEPTABLE_FILE_NAMES}
+	}
+
+	config, err := ReadConfig(configPath, "", "", true)
+	if err != nil {
+		return Config{}, []Project{}, err
+	}
+
+	found := []Project{}
+	if _, err := os.Stat(filepath.Join(configDir, ".git")); err == nil {
+		url, err := core.GetWdRemoteURL(configDir)
+		if err != nil {
+			return Config{}, []Project{}, err
+		}
+		found = append(found, Project{Name: filepath.Base(configDir), Path: ".", URL: url})
+	}
+
+	prs, err := FindVCSystems(configDir)
+	if err != nil {
+		return Config{}, []Project{}, err
+	}
+	found = append(found, prs...)
+
+	var added []Project
+	var updated []Project
+	for _, p := range found {
+		absPath := filepath.Join(configDir, p.Path)
+
+		project := config.getProjectByPath(absPath)
+		if project == nil {
+			added = append(added, p)
+			continue
+		}
+
+		// FindVCSystems only discovers worktrees for nested repositories
+		if p.Path == "." {
+			worktrees, _ := core.GetWorktreeList(configDir)
+			for wtPath, branch := range worktrees {
+				if branch == "" {
+					continue
+				}
+				wtRelPath, _ := filepath.Rel(configDir, wtPath)
+				p.WorktreeList = append(p.WorktreeList, Worktree{Path: wtRelPath, Branch: branch})
+			}
+		}
+
+		var worktrees []Worktree
+		for _, wt := range p.WorktreeList {
+			if !project.hasWorktree(filepath.Join(absPath, wt.Path)) {
+				worktrees = append(worktrees, wt)
+			}
+		}
+
+		if len(worktrees) > 0 {
+			slices.SortFunc(worktrees, func(a, b Worktree) int { return strings.Compare(a.Path, b.Path) })
+			for _, wt := range worktrees {
+				err := config.AddProjectWorktree(project.Name, wt)
+				if err != nil {
+					return Config{}, []Project{}, err
+				}
+			}
+			updated = append(updated, Project{Name: project.Name, Path: project.RelPath, WorktreeList: worktrees})
+		}
+	}
+
+	if len(added) > 0 {
+		for i := range added {
+			slices.SortFunc(added[i].WorktreeList, func(a, b Worktree) int { return strings.Compare(a.Path, b.Path) })
+		}
+
+		err = config.addProjects(added)
+		if err != nil {
+			return Config{}, []Project{}, err
+		}
+	}
+
+	// Read config again to include the new projects
+	config, err = ReadConfig(configPath, "", "", true)
+	if err != nil {
+		return Config{}, []Project{}, err
+	}
+
+	return config, append(added, updated...), nil
+}
+
+// addProjects adds projects to the projects section of the main config file.
+// Projects with duplicate names are renamed to their path.
+func (c Config) addProjects(projects []Project) error {
+	RenameDuplicates(projects)
+	names := c.GetProjectNames()
+	for i := range projects {
+		if slices.Contains(names, projects[i].Name) {
+			projects[i].Name = projects[i].Path
+		}
+	}
+
+	f, err := ReadYAMLFile(c.Path)
+	if err != nil {
+		return err
+	}
+
+	section := f.Section("projects")
+	for _, p := range projects {
+		SetMappingValue(section, p.Name, ProjectNode(p))
+	}
+
+	return f.Write()
+}
+
 func RenameDuplicates(projects []Project) {
 	projectNamesCount := make(map[string]int)
 	// Find duplicate names
@@ -0,0 +1,230 @@
+package dao
+
+import (
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/alajmo/mani/core"
+)
+
+// Project fields which can be set with `mani project set`
+var (
+	projectFields     = []string{"path", "desc", "url", "clone", "branch", "single_branch", "sync"}
+	projectBoolFields = []string{"single_branch", "sync"}
+)
+
+// readEditableFile reads the config file declaring a resource, cached remote
+// imports are replaced when fetched and cannot be edited
+func readEditableFile(path string) (*YAMLFile, error) {
+	cacheDir, err := GetImportCacheDir()
+	if err == nil && strings.HasPrefix(path, cacheDir+string(filepath.Separator)) {
+		return nil, &core.ConfigReadOnly{Path: path}
+	}
+
+	return ReadYAMLFile(path)
+}
+
+// AddProject adds the project to the main config file
+func (c Config) AddProject(project Project) error {
+	for _, p := range c.ProjectList {
+		if p.Name == project.Name {
+			return &core.DuplicateResource{Kind: "project", Name: p.Name, Context: p.context, Line: p.contextLine}
+		}
+	}
+
+	f, err := readEditableFile(c.Path)
+	if err != nil {
+		return err
+	}
+
+	SetMappingValue(f.Section("projects"), project.Name, ProjectNode(project))
+
+	return f.Write()
+}
+
+// RemoveProject removes the project from the config file that declares it
+func (c Config) RemoveProject(name string) error {
+	project, err := c.GetProject(name)
+	if err != nil {
+		return err
+	}
+
+	f, err := readEditableFile(project.context)
+	if err != nil {
+		return err
+	}
+
+	projects := MappingValue(f.Root(), "projects")
+	if projects == nil || !RemoveMappingKey(projects, unqualifyName(project.Namespace, project.Name)) {
+		return &core.ProjectNotFound{Name: []string{name}}
+	}
+
+	return f.Write()
+}
+
+// SetProjectField sets a field of the project in the config file that declares
+// it, an empty value removes the field
+func (c Config) SetProjectField(name string, key string, value string) error {
+	if !slices.Contains(projectFields, key) {
+		return &core.ProjectFieldInvalid{Key: key, Fields: projectFields}
+	}
+
+	field := ScalarNode(value)
+	if slices.Contains(projectBoolFields, key) && value != "" {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return &core.ProjectFieldValueInvalid{Key: key, Value: value}
+		}
+		field = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(b)}
+	}
+
+	return c.editProject(name, func(node *yaml.Node) error {
+		if value == "" {
+			RemoveMappingKey(node, key)
+			return nil
+		}
+
+		// Keep comments of the existing value
+		if existing := MappingValue(node, key); existing != nil && existing.Kind == yaml.ScalarNode {
+			existing.Value = field.Value
+			existing.Tag = field.Tag
+			existing.Style = 0
+			return nil
+		}
+
+		SetMappingValue(node, key, field)
+		return nil
+	})
+}
+
+// AddProjectTag adds the tag to the project in the config file that declares it
+func (c Config) AddProjectTag(name string, tag string) error {
+	return c.editProject(name, func(node *yaml.Node) error {
+		tags := MappingValue(node, "tags")
+		if tags == nil || tags.Kind != yaml.SequenceNode {
+			tags = &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+			SetMappingValue(node, "tags", tags)
+		}
+
+		for _, t := range tags.Content {
+			if t.Value == tag {
+				return &core.TagAlreadyExists{Project: name, Tag: tag}
+			}
+		}
+		tags.Content = append(tags.Content, ScalarNode(tag))
+
+		return nil
+	})
+}
+
+// RemoveProjectTag removes the tag from the project in the config file that declares it
+func (c Config) RemoveProjectTag(name string, tag string) error {
+	return c.editProject(name, func(node *yaml.Node) error {
+		tags := MappingValue(node, "tags")
+		if tags == nil {
+			return &core.TagNotFound{Tags: []string{tag}}
+		}
+
+		i := slices.IndexFunc(tags.Content, func(t *yaml.Node) bool { return t.Value == tag })
+		if i < 0 {
+			return &core.TagNotFound{Tags: []string{tag}}
+		}
+		tags.Content = slices.Delete(tags.Content, i, i+1)
+
+		if len(tags.Content) == 0 {
+			RemoveMappingKey(node, "tags")
+		}
+
+		return nil
+	})
+}
+
+// editProject calls edit with the node of the project in the config file that
+// declares it, and writes the file
+func (c Config) editProject(name string, edit func(node *yaml.Node) error) error {
+	project, err := c.GetProject(name)
+	if err != nil {
+		return err
+	}
+
+	f, err := readEditableFile(project.context)
+	if err != nil {
+		return err
+	}
+
+	node := MappingValue(MappingValue(f.Root(), "projects"), unqualifyName(project.Namespace, project.Name))
+	if node == nil {
+		return &core.ProjectNotFound{Name: []string{name}}
+	}
+
+	// Shorthand project definition without any fields, `project:`
+	if node.Kind != yaml.MappingNode {
+		*node = yaml.Node{Kind: yaml.MappingNode, Line: node.Line, Column: node.Column}
+	}
+
+	err = edit(node)
+	if err != nil {
+		return err
+	}
+
+	if len(node.Content) == 0 {
+		*node = yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Line: node.Line, Column: node.Column}
+	}
+
+	return f.Write()
+}
+
+// AddTask adds the task to the main config file, tasks without description
+// use the shorthand definition, `name: cmd`
+func (c Config) AddTask(name string, cmd string, desc string) error {
+	for _, t := range c.TaskList {
+		if t.Name == name {
+			return &core.DuplicateResource{Kind: "task", Name: t.Name, Context: t.context, Line: t.contextLine}
+		}
+	}
+
+	f, err := readEditableFile(c.Path)
+	if err != nil {
+		return err
+	}
+
+	cmdNode := ScalarNode(cmd)
+	if strings.Contains(cmd, "\n") {
+		cmdNode.Style = yaml.LiteralStyle
+	}
+
+	node := cmdNode
+	if desc != "" {
+		node = &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{
+			ScalarNode("desc"), ScalarNode(desc),
+			ScalarNode("cmd"), cmdNode,
+		}}
+	}
+	SetMappingValue(f.Section("tasks"), name, node)
+
+	return f.Write()
+}
+
+// RemoveTask removes the task from the config file that declares it
+func (c Config) RemoveTask(name string) error {
+	task, err := c.GetTask(name)
+	if err != nil {
+		return err
+	}
+
+	f, err := readEditableFile(task.context)
+	if err != nil {
+		return err
+	}
+
+	tasks := MappingValue(f.Root(), "tasks")
+	if tasks == nil || !RemoveMappingKey(tasks, unqualifyName(task.Namespace, task.Name)) {
+		return &core.TaskNotFound{Name: []string{name}}
+	}
+
+	return f.Write()
+}
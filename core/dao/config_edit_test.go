@@ -0,0 +1,107 @@
+package dao
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConfig_Edit(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"mani.yaml": `import:
+  - team.yaml
+
+# Projects
+projects:
+  api:
+    path: api # the api
+    sync: true
+
+tasks:
+  status: git status
+`,
+		"team.yaml": `projects:
+  # Team project
+  lib:
+    tags: [core]
+
+tasks:
+  # Lint all
+  lint: make lint
+
+  fmt: make fmt
+`,
+	})
+
+	read := func() *Config {
+		config, err := ReadConfig(filepath.Join(dir, "mani.yaml"), "", "", false)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return &config
+	}
+
+	edits := []func(c *Config) error{
+		func(c *Config) error {
+			return c.AddProject(Project{Name: "cli", URL: "git@example.com:cli.git", Tags: []string{"go"}})
+		},
+		func(c *Config) error { return c.SetProjectField("api", "path", "services/api") },
+		func(c *Config) error { return c.SetProjectField("api", "sync", "false") },
+		func(c *Config) error { return c.AddProjectTag("lib", "go") },
+		func(c *Config) error { return c.RemoveProjectTag("lib", "core") },
+		func(c *Config) error { return c.AddTask("build", "make", "Build all") },
+		func(c *Config) error { return c.RemoveTask("lint") },
+		func(c *Config) error { return c.RemoveProject("cli") },
+	}
+	for _, edit := range edits {
+		if err := edit(read()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	expected := map[string]string{
+		"mani.yaml": `import:
+  - team.yaml
+
+# Projects
+projects:
+  api:
+    path: services/api # the api
+    sync: false
+
+tasks:
+  status: git status
+  build:
+    desc: Build all
+    cmd: make
+`,
+		"team.yaml": `projects:
+  # Team project
+  lib:
+    tags: [go]
+
+tasks:
+  fmt: make fmt
+`,
+	}
+	for name, content := range expected {
+		dat, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(dat) != content {
+			t.Errorf("%s: expected:\n%s\ngot:\n%s", name, content, dat)
+		}
+	}
+
+	if err := read().SetProjectField("api", "unknown", "x"); err == nil {
+		t.Errorf("expected invalid field error")
+	}
+	if err := read().AddProjectTag("lib", "go"); err == nil {
+		t.Errorf("expected duplicate tag error")
+	}
+	if err := read().AddTask("status", "git status", ""); err == nil {
+		t.Errorf("expected duplicate task error")
+	}
+}
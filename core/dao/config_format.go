@@ -0,0 +1,438 @@
+package dao
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2/unstable"
+	"gopkg.in/yaml.v3"
+
+	"github.com/alajmo/mani/core"
+)
+
+// CONFIG_EXTENSIONS are the extensions of config files. TOML and JSON configs
+// are converted to the same yaml.Node representation as YAML configs.
+var CONFIG_EXTENSIONS = []string{".yaml", ".yml", ".toml", ".json"}
+
+func isYAMLFile(path string) bool {
+	ext := filepath.Ext(path)
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// parseConfigFile parses a YAML, TOML or JSON config into a yaml document node,
+// the format is determined by the file extension. Nodes keep the line numbers
+// of the source file.
+func parseConfigFile(path string, dat []byte) (*yaml.Node, error) {
+	switch filepath.Ext(path) {
+	case ".toml":
+		return parseTOML(dat)
+	case ".json":
+		return parseJSON(dat)
+	}
+
+	var doc yaml.Node
+	err := yaml.Unmarshal(dat, &doc)
+	if err != nil {
+		return nil, err
+	}
+
+	return &doc, nil
+}
+
+// decodeConfigFile parses a YAML, TOML or JSON config and decodes it into out
+func decodeConfigFile(path string, dat []byte, out any) error {
+	doc, err := parseConfigFile(path, dat)
+	if err != nil {
+		return err
+	}
+
+	// Empty file
+	if len(doc.Content) == 0 {
+		return nil
+	}
+
+	return doc.Decode(out)
+}
+
+// parseJSON converts a JSON document to a yaml document node. The document
+// is walked token by token instead of being parsed as YAML, since YAML and
+// JSON strings have different escape sequences.
+func parseJSON(dat []byte) (*yaml.Node, error) {
+	// Empty file
+	if len(bytes.TrimSpace(dat)) == 0 {
+		return &yaml.Node{}, nil
+	}
+
+	var v any
+	err := json.Unmarshal(dat, &v)
+	if syntaxErr, ok := err.(*json.SyntaxError); ok {
+		line := bytes.Count(dat[:syntaxErr.Offset], []byte("\n")) + 1
+		return nil, fmt.Errorf("json: line %d: %s", line, syntaxErr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	j := jsonConverter{dat: dat, decoder: json.NewDecoder(bytes.NewReader(dat)), line: 1}
+	j.decoder.UseNumber()
+
+	root, err := j.value()
+	if err != nil {
+		return nil, err
+	}
+
+	return &yaml.Node{Kind: yaml.DocumentNode, Line: 1, Column: 1, Content: []*yaml.Node{root}}, nil
+}
+
+type jsonConverter struct {
+	dat     []byte
+	decoder *json.Decoder
+
+	// Position of the last token, offsets only increase so lines are counted
+	// from the previous token
+	offset     int
+	line       int
+	lineOffset int
+}
+
+// token returns the next token and the yaml node positioned at its start
+func (j *jsonConverter) token() (json.Token, *yaml.Node, error) {
+	start := int(j.decoder.InputOffset())
+	token, err := j.decoder.Token()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Skip the whitespace and separators before the token
+	for start < len(j.dat) && strings.ContainsRune(" \t\r\n,:", rune(j.dat[start])) {
+		start++
+	}
+	for ; j.offset < start; j.offset++ {
+		if j.dat[j.offset] == '\n' {
+			j.line++
+			j.lineOffset = j.offset + 1
+		}
+	}
+
+	return token, &yaml.Node{Line: j.line, Column: start - j.lineOffset + 1}, nil
+}
+
+func (j *jsonConverter) value() (*yaml.Node, error) {
+	token, node, err := j.token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := token.(type) {
+	case json.Delim:
+		if t == '{' {
+			node.Kind = yaml.MappingNode
+			node.Tag = "!!map"
+			for j.decoder.More() {
+				name, key, err := j.token()
+				if err != nil {
+					return nil, err
+				}
+				key.Kind = yaml.ScalarNode
+				key.Tag = "!!str"
+				key.Value = name.(string)
+
+				value, err := j.value()
+				if err != nil {
+					return nil, err
+				}
+				node.Content = append(node.Content, key, value)
+			}
+		} else {
+			node.Kind = yaml.SequenceNode
+			node.Tag = "!!seq"
+			for j.decoder.More() {
+				item, err := j.value()
+				if err != nil {
+					return nil, err
+				}
+				node.Content = append(node.Content, item)
+			}
+		}
+
+		// Closing delimiter
+		_, _, err = j.token()
+		if err != nil {
+			return nil, err
+		}
+	case string:
+		node.Kind = yaml.ScalarNode
+		node.Tag = "!!str"
+		node.Value = t
+	case json.Number:
+		node.Kind = yaml.ScalarNode
+		node.Tag = "!!float"
+		if _, err := t.Int64(); err == nil {
+			node.Tag = "!!int"
+		}
+		node.Value = t.String()
+	case bool:
+		node.Kind = yaml.ScalarNode
+		node.Tag = "!!bool"
+		node.Value = strconv.FormatBool(t)
+	case nil:
+		node.Kind = yaml.ScalarNode
+		node.Tag = "!!null"
+		node.Value = "null"
+	}
+
+	return node, nil
+}
+
+// parseTOML converts a TOML document to a yaml document node. Tables are
+// converted to mappings and arrays of tables to sequences of mappings.
+//
+// The document is read with the go-toml unstable parser, since the stable
+// decoder doesn't keep line numbers. Its API may change in minor releases,
+// check parseTOML when upgrading go-toml in go.mod.
+func parseTOML(dat []byte) (*yaml.Node, error) {
+	t := tomlConverter{tables: make(map[*yaml.Node]tomlTable)}
+	t.parser.Reset(dat)
+
+	root := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: 1, Column: 1}
+	table := root
+	for t.parser.NextExpression() {
+		expr := t.parser.Expression()
+
+		var err error
+		switch expr.Kind {
+		case unstable.KeyValue:
+			err = t.setKeyValue(table, expr)
+		case unstable.Table:
+			table, err = t.table(root, expr)
+		case unstable.ArrayTable:
+			table, err = t.arrayTable(root, expr)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := t.parser.Error(); err != nil {
+		if parserErr, ok := err.(*unstable.ParserError); ok {
+			line := t.parser.Shape(t.parser.Range(parserErr.Highlight)).Start.Line
+			return nil, fmt.Errorf("toml: line %d: %s", line, parserErr.Message)
+		}
+		return nil, err
+	}
+
+	return &yaml.Node{Kind: yaml.DocumentNode, Line: 1, Column: 1, Content: []*yaml.Node{root}}, nil
+}
+
+// tomlTable is how a table or array was defined, which determines if it can
+// be extended later in the document
+type tomlTable int
+
+const (
+	tomlImplicit      tomlTable = iota // parent of a [table] header, can be defined once
+	tomlHeader                         // defined by a [table] header
+	tomlDotted                         // defined by a dotted key, can be extended by dotted keys and sub-table headers
+	tomlInline                         // inline table, can't be extended
+	tomlArray                          // static array, can't be extended
+	tomlArrayOfTables                  // defined by [[table]] headers, extended by more [[table]] headers
+)
+
+type tomlConverter struct {
+	parser unstable.Parser
+	tables map[*yaml.Node]tomlTable
+}
+
+// line returns the line of the node in the source, or fallback if the node
+// doesn't reference the source
+func (t *tomlConverter) line(node *unstable.Node, fallback int) int {
+	if node.Raw.Length == 0 {
+		return fallback
+	}
+
+	return t.parser.Shape(node.Raw).Start.Line
+}
+
+// keys returns the parts of a dotted key as yaml scalar nodes
+func (t *tomlConverter) keys(it unstable.Iterator) []*yaml.Node {
+	var keys []*yaml.Node
+	for it.Next() {
+		key := ScalarNode(string(it.Node().Data))
+		key.Line = t.line(it.Node(), 0)
+		key.Column = 1
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+// walk returns the mapping of a dotted key, creating the tables that don't
+// exist as kind. Arrays of tables resolve to their last table. Dotted keys
+// can only walk tables defined by dotted keys, headers can walk all tables
+// except inline tables and static arrays.
+func (t *tomlConverter) walk(node *yaml.Node, keys []*yaml.Node, kind tomlTable) (*yaml.Node, error) {
+	for _, key := range keys {
+		child := MappingValue(node, key.Value)
+		if child == nil {
+			child = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: key.Line, Column: key.Column}
+			node.Content = append(node.Content, key, child)
+			t.tables[child] = kind
+		}
+
+		switch t.tables[child] {
+		case tomlInline, tomlArray:
+			return nil, &core.TOMLKeyRedefined{Key: key.Value, Line: key.Line}
+		case tomlArrayOfTables:
+			if kind == tomlDotted {
+				return nil, &core.TOMLKeyRedefined{Key: key.Value, Line: key.Line}
+			}
+			child = child.Content[len(child.Content)-1]
+		case tomlImplicit, tomlHeader:
+			if kind == tomlDotted {
+				return nil, &core.TOMLKeyRedefined{Key: key.Value, Line: key.Line}
+			}
+		}
+		if child.Kind != yaml.MappingNode {
+			return nil, &core.TOMLKeyRedefined{Key: key.Value, Line: key.Line}
+		}
+
+		node = child
+	}
+
+	return node, nil
+}
+
+func (t *tomlConverter) setKeyValue(table *yaml.Node, expr *unstable.Node) error {
+	keys := t.keys(expr.Key())
+	node, err := t.walk(table, keys[:len(keys)-1], tomlDotted)
+	if err != nil {
+		return err
+	}
+
+	key := keys[len(keys)-1]
+	if MappingValue(node, key.Value) != nil {
+		return &core.TOMLKeyRedefined{Key: key.Value, Line: key.Line}
+	}
+
+	value, err := t.value(expr.Value(), key.Line)
+	if err != nil {
+		return err
+	}
+	node.Content = append(node.Content, key, value)
+
+	return nil
+}
+
+// table returns the mapping of a [table] header, a table can only be defined
+// once, unless it was created as the parent of another table
+func (t *tomlConverter) table(root *yaml.Node, expr *unstable.Node) (*yaml.Node, error) {
+	keys := t.keys(expr.Key())
+	node, err := t.walk(root, keys[:len(keys)-1], tomlImplicit)
+	if err != nil {
+		return nil, err
+	}
+
+	key := keys[len(keys)-1]
+	table := MappingValue(node, key.Value)
+	if table == nil {
+		table = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: key.Line, Column: key.Column}
+		node.Content = append(node.Content, key, table)
+	} else if table.Kind != yaml.MappingNode || t.tables[table] != tomlImplicit {
+		return nil, &core.TOMLKeyRedefined{Key: key.Value, Line: key.Line}
+	}
+	t.tables[table] = tomlHeader
+
+	return table, nil
+}
+
+func (t *tomlConverter) arrayTable(root *yaml.Node, expr *unstable.Node) (*yaml.Node, error) {
+	keys := t.keys(expr.Key())
+	node, err := t.walk(root, keys[:len(keys)-1], tomlImplicit)
+	if err != nil {
+		return nil, err
+	}
+
+	key := keys[len(keys)-1]
+	seq := MappingValue(node, key.Value)
+	if seq == nil {
+		seq = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Line: key.Line, Column: key.Column}
+		node.Content = append(node.Content, key, seq)
+		t.tables[seq] = tomlArrayOfTables
+	}
+	if t.tables[seq] != tomlArrayOfTables {
+		return nil, &core.TOMLKeyRedefined{Key: key.Value, Line: key.Line}
+	}
+
+	table := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: key.Line, Column: key.Column}
+	seq.Content = append(seq.Content, table)
+	t.tables[table] = tomlHeader
+
+	return table, nil
+}
+
+// value converts a TOML value, line is the line of its key
+func (t *tomlConverter) value(v *unstable.Node, line int) (*yaml.Node, error) {
+	line = t.line(v, line)
+	data := string(v.Data)
+	node := &yaml.Node{Kind: yaml.ScalarNode, Line: line, Column: 1}
+
+	switch v.Kind {
+	case unstable.String:
+		node.Tag = "!!str"
+		node.Value = data
+	case unstable.Bool:
+		node.Tag = "!!bool"
+		node.Value = data
+	case unstable.Integer:
+		i, err := strconv.ParseInt(strings.ReplaceAll(data, "_", ""), 0, 64)
+		if err != nil {
+			return nil, fmt.Errorf("toml: line %d: invalid integer `%s`", line, data)
+		}
+		node.Tag = "!!int"
+		node.Value = strconv.FormatInt(i, 10)
+	case unstable.Float:
+		node.Tag = "!!float"
+		node.Value = strings.ReplaceAll(data, "_", "")
+		switch strings.TrimPrefix(node.Value, "+") {
+		case "inf":
+			node.Value = ".inf"
+		case "-inf":
+			node.Value = "-.inf"
+		case "nan", "-nan":
+			node.Value = ".nan"
+		}
+	case unstable.LocalDate, unstable.LocalTime, unstable.LocalDateTime, unstable.DateTime:
+		node.Tag = "!!str"
+		node.Value = data
+	case unstable.Array:
+		node.Kind = yaml.SequenceNode
+		node.Tag = "!!seq"
+		t.tables[node] = tomlArray
+		it := v.Children()
+		for it.Next() {
+			item, err := t.value(it.Node(), line)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, item)
+		}
+	case unstable.InlineTable:
+		node.Kind = yaml.MappingNode
+		node.Tag = "!!map"
+		t.tables[node] = tomlInline
+		it := v.Children()
+		for it.Next() {
+			err := t.setKeyValue(node, it.Node())
+			if err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, fmt.Errorf("toml: line %d: unsupported value `%s`", line, data)
+	}
+
+	return node, nil
+}
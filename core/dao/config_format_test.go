@@ -0,0 +1,186 @@
+package dao
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alajmo/mani/core"
+)
+
+func TestConfig_Formats(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"mani.toml": `# Main config
+import = ["tasks.json"]
+
+[env]
+AUTHOR = "alajmo"
+
+[projects.api]
+url = "git@example.com:api.git"
+tags = ["go", "core"]
+
+[projects.web]
+path = "frontend/web"
+sync = false
+
+[specs.fast]
+parallel = true
+forks = 0x10
+
+[[tasks.build.commands]]
+name = "one"
+cmd = "make one"
+
+[[tasks.build.commands]]
+name = "two"
+cmd = "make two"
+`,
+		"tasks.json": `{
+  "tasks": {
+    "lint": {
+      "desc": "Lint all",
+      "cmd": "make lint"
+    }
+  }
+}
+`,
+	})
+
+	config, err := ReadConfig(filepath.Join(dir, "mani.toml"), "", "", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	api, err := config.GetProject("api")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if api.URL != "git@example.com:api.git" || strings.Join(api.Tags, ",") != "go,core" || api.GetContextLine() != 7 {
+		t.Fatalf("unexpected project %s: url %s, tags %v, line %d", api.Name, api.URL, api.Tags, api.GetContextLine())
+	}
+
+	web, err := config.GetProject("web")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if web.RelPath != "frontend/web" || web.IsSync() || web.GetContextLine() != 11 {
+		t.Fatalf("unexpected project %s: path %s, line %d", web.Name, web.RelPath, web.GetContextLine())
+	}
+
+	spec, err := config.GetSpec("fast")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !spec.Parallel || spec.Forks != 16 {
+		t.Fatalf("unexpected spec %+v", spec)
+	}
+
+	build, err := config.GetTask("build")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(build.Commands) != 2 || build.Commands[1].Cmd != "make two" {
+		t.Fatalf("unexpected commands %+v", build.Commands)
+	}
+
+	lint, err := config.GetTask("lint")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if lint.Desc != "Lint all" || lint.GetContextLine() != 3 || filepath.Base(lint.GetContext()) != "tasks.json" {
+		t.Fatalf("unexpected task %s: desc %s, %s:%d", lint.Name, lint.Desc, lint.GetContext(), lint.GetContextLine())
+	}
+}
+
+func TestConfig_FormatErrors(t *testing.T) {
+	tests := []struct {
+		file     string
+		content  string
+		expected string
+	}{
+		{file: "mani.toml", content: "[tasks]\nhello = \"echo\"\nhello = \"echo\"\n", expected: "mani.toml:3"},
+		{file: "mani.toml", content: "[tasks\n", expected: "mani.toml:1"},
+		{file: "mani.toml", content: "[tasks.hello]\ncmd = 1\nshell = [1]\n", expected: "mani.toml:3"},
+		{file: "mani.json", content: "{\n  \"tasks\": {\n    \"a\": \"b\",\n  }\n}\n", expected: "mani.json:4"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			dir := t.TempDir()
+			writeTestFiles(t, dir, map[string]string{tt.file: tt.content})
+
+			_, err := ReadConfig(filepath.Join(dir, tt.file), "", "", false)
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Fatalf("expected error at %s, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestConfig_JSONEscapes(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"mani.json": `{
+  "projects": {"api": {"tags": [1, 2.5, true, null]}},
+  "tasks": {
+    "slash": {"cmd": "echo \/tmp"},
+    "emoji": {
+      "cmd": "echo \ud83d\ude00 \u00e9\t"
+    }
+  }
+}
+`,
+	})
+
+	config, err := ReadConfig(filepath.Join(dir, "mani.json"), "", "", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for name, expected := range map[string]string{"slash": "echo /tmp", "emoji": "echo \U0001F600 \u00e9\t"} {
+		task, err := config.GetTask(name)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if task.Cmd != expected {
+			t.Errorf("expected task %s cmd %q, got %q", name, expected, task.Cmd)
+		}
+	}
+
+	task, _ := config.GetTask("emoji")
+	if task.GetContextLine() != 5 {
+		t.Errorf("expected task on line 5, got %d", task.GetContextLine())
+	}
+}
+
+func TestConfig_TOMLTables(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		valid   bool
+	}{
+		{name: "parent table defined after sub-table", content: "[projects.api.env]\nA = \"a\"\n[projects.api]\npath = \"api\"\n", valid: true},
+		{name: "sub-table of dotted key table", content: "[projects]\napi.path = \"api\"\n[projects.api.env]\nA = \"a\"\n", valid: true},
+		{name: "array of tables", content: "[[targets.all.paths]]\n[[targets.all.paths]]\n", valid: true},
+		{name: "table defined twice", content: "[projects.api]\npath = \"api\"\n[projects.api]\nurl = \"url\"\n"},
+		{name: "table defined by dotted key", content: "[projects]\napi.path = \"api\"\n[projects.api]\nurl = \"url\"\n"},
+		{name: "dotted key extends table", content: "[projects.api]\npath = \"api\"\n[projects]\napi.url = \"url\"\n"},
+		{name: "inline table extended", content: "[projects]\napi = { path = \"api\" }\n[projects.api]\nurl = \"url\"\n"},
+		{name: "array of tables redefined as table", content: "[[projects]]\n[projects]\n"},
+		{name: "static array extended", content: "projects = []\n[[projects]]\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseTOML([]byte(tt.content))
+			if tt.valid && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if _, ok := err.(*core.TOMLKeyRedefined); !tt.valid && !ok {
+				t.Fatalf("expected TOMLKeyRedefined, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package dao
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gookit/color"
+)
+
+type diffLine struct {
+	op   byte // ' ', '-' or '+'
+	text string
+}
+
+// FormatDiff returns a unified diff of the changes from a to b, or an empty
+// string if they are equal
+func FormatDiff(path string, a []byte, b []byte) string {
+	lines := diffLines(splitLines(string(a)), splitLines(string(b)))
+
+	const context = 3
+	var msg strings.Builder
+	for start := 0; start < len(lines); {
+		// Find next change
+		for start < len(lines) && lines[start].op == ' ' {
+			start++
+		}
+		if start == len(lines) {
+			break
+		}
+
+		// Extend the hunk until more than 2*context unchanged lines follow a change
+		end := start
+		for i := start; i < len(lines); i++ {
+			if lines[i].op != ' ' {
+				end = i + 1
+			} else if i-end >= 2*context {
+				break
+			}
+		}
+
+		from := max(start-context, 0)
+		to := min(end+context, len(lines))
+
+		if msg.Len() == 0 {
+			msg.WriteString(color.Bold.Sprintf("--- %s", path) + "\n")
+			msg.WriteString(color.Bold.Sprintf("+++ %s", path) + "\n")
+		}
+		msg.WriteString(color.FgCyan.Sprintf("@@ -%s +%s @@", hunkRange(lines, from, to, '+'), hunkRange(lines, from, to, '-')) + "\n")
+		for _, line := range lines[from:to] {
+			switch line.op {
+			case '-':
+				msg.WriteString(color.FgRed.Sprintf("-%s", line.text) + "\n")
+			case '+':
+				msg.WriteString(color.FgGreen.Sprintf("+%s", line.text) + "\n")
+			default:
+				msg.WriteString(" " + line.text + "\n")
+			}
+		}
+
+		start = to
+	}
+
+	return msg.String()
+}
+
+// hunkRange returns the start line and line count of lines[from:to] in the
+// file which doesn't contain the lines with op skip
+func hunkRange(lines []diffLine, from int, to int, skip byte) string {
+	line := 1
+	for _, l := range lines[:from] {
+		if l.op != skip {
+			line++
+		}
+	}
+
+	count := 0
+	for _, l := range lines[from:to] {
+		if l.op != skip {
+			count++
+		}
+	}
+
+	if count == 0 {
+		line--
+	}
+
+	return fmt.Sprintf("%d,%d", line, count)
+}
+
+// diffLines returns the lines of a and b, marked as removed, added or
+// unchanged using the longest common subsequence
+func diffLines(a []string, b []string) []diffLine {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var lines []diffLine
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			lines = append(lines, diffLine{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, diffLine{'-', a[i]})
+			i++
+		default:
+			lines = append(lines, diffLine{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		lines = append(lines, diffLine{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		lines = append(lines, diffLine{'+', b[j]})
+	}
+
+	return lines
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
@@ -0,0 +1,150 @@
+package dao
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/alajmo/mani/core"
+)
+
+// The time each cached env variable was cached at by key=value
+var (
+	envCachedAt = map[string]time.Time{}
+	envCacheMu  sync.Mutex
+)
+
+// EnvCache holds how long the evaluated value of each env variable is cached
+// by name, 0 for values which are not cached
+type EnvCache map[string]time.Duration
+
+// GetEnvCacheDir returns the directory evaluated env values are cached in
+func GetEnvCacheDir() (string, error) {
+	if dir, present := os.LookupEnv("MANI_CACHE_DIR"); present {
+		return filepath.Join(dir, "env"), nil
+	}
+
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "mani", "env"), nil
+}
+
+// ParseEnvCache returns the cache durations of the env. Values are either
+// scalars or mappings with value and cache, the cache must be a duration.
+func ParseEnvCache(node yaml.Node) (EnvCache, []error) {
+	cache := EnvCache{}
+	var errs []error
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		name := node.Content[i].Value
+		cache[name] = 0
+
+		if node.Content[i+1].Kind != yaml.MappingNode {
+			continue
+		}
+
+		value := MappingValue(node.Content[i+1], "cache")
+		if value == nil || value.Value == "" {
+			continue
+		}
+
+		ttl, err := time.ParseDuration(value.Value)
+		if err != nil || ttl < 0 {
+			errs = append(errs, &core.EnvCacheInvalid{Name: name, Cache: value.Value})
+			continue
+		}
+		cache[name] = ttl
+	}
+
+	return cache, errs
+}
+
+// MergeEnvCaches merges cache durations, the first cache takes precedence
+// like in MergeEnvs
+func MergeEnvCaches(caches ...EnvCache) EnvCache {
+	merged := EnvCache{}
+	for _, cache := range caches {
+		for name, ttl := range cache {
+			if _, ok := merged[name]; !ok {
+				merged[name] = ttl
+			}
+		}
+	}
+
+	return merged
+}
+
+// envCachePath returns the cache file of the command, commands are cached per
+// working directory since they're evaluated in it
+func envCachePath(cmdStr string) (string, error) {
+	dir, err := GetEnvCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256([]byte(wd + "\n" + cmdStr))
+	return filepath.Join(dir, hex.EncodeToString(sum[:8])), nil
+}
+
+func readEnvCache(name string, cmdStr string, ttl time.Duration) (string, bool) {
+	path, err := envCachePath(cmdStr)
+	if err != nil {
+		return "", false
+	}
+
+	info, err := os.Stat(path)
+	if err != nil || time.Since(info.ModTime()) > ttl {
+		return "", false
+	}
+
+	dat, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+
+	envCacheMu.Lock()
+	envCachedAt[name+"="+strings.TrimSuffix(string(dat), "\n")] = info.ModTime()
+	envCacheMu.Unlock()
+
+	return string(dat), true
+}
+
+// writeEnvCache caches the output of the command, failing to write the cache
+// only means the command is evaluated again next time
+func writeEnvCache(cmdStr string, out string) {
+	path, err := envCachePath(cmdStr)
+	if err != nil {
+		return
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0o700)
+	if err != nil {
+		return
+	}
+
+	_ = os.WriteFile(path, []byte(out), 0o600)
+}
+
+// EnvCachedAt returns when the env variable in the form key=value was cached,
+// if its value was read from the env cache
+func EnvCachedAt(env string) (time.Time, bool) {
+	envCacheMu.Lock()
+	defer envCacheMu.Unlock()
+
+	cachedAt, ok := envCachedAt[strings.TrimSuffix(env, "\n")]
+	return cachedAt, ok
+}
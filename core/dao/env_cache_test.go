@@ -0,0 +1,98 @@
+package dao
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestEnv_Cache(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MANI_CACHE_DIR", filepath.Join(dir, "cache"))
+	counter := filepath.Join(dir, "counter")
+
+	var node yaml.Node
+	err := yaml.Unmarshal([]byte(`
+CACHED:
+  value: $(echo x >> `+counter+`; wc -l < `+counter+` | tr -d ' ')
+  cache: 5m
+PLAIN: $(echo plain)
+`), &node)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cache, errs := ParseEnvCache(*node.Content[0])
+	if len(errs) > 0 || cache["CACHED"] != 5*time.Minute || cache["PLAIN"] != 0 {
+		t.Fatalf("unexpected cache %v, errors %v", cache, errs)
+	}
+
+	for range 2 {
+		envs, err := EvaluateEnv(ParseNodeEnv(*node.Content[0]), cache)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if envs[0] != "CACHED=1\n" || envs[1] != "PLAIN=plain\n" {
+			t.Errorf("unexpected env %q", envs)
+		}
+	}
+
+	if _, ok := EnvCachedAt("CACHED=1"); !ok {
+		t.Errorf("expected CACHED to be read from the cache")
+	}
+	if _, ok := EnvCachedAt("PLAIN=plain"); ok {
+		t.Errorf("expected PLAIN not to be cached")
+	}
+
+	// The same command is only cached for the env declaring a cache
+	envs, err := EvaluateEnv([]string{"OTHER=" + MappingValue(node.Content[0], "CACHED").Content[1].Value}, nil)
+	if err != nil || envs[0] != "OTHER=2\n" {
+		t.Errorf("expected OTHER to be evaluated, got %q, %v", envs, err)
+	}
+
+	// Cache durations are validated when the config is read
+	writeTestFiles(t, dir, map[string]string{
+		"mani.yaml": "tasks:\n  build:\n    env:\n      INVALID:\n        value: $(echo a)\n        cache: soon\n    cmd: make\n",
+	})
+	_, err = ReadConfig(filepath.Join(dir, "mani.yaml"), "", "", false)
+	if err == nil || !strings.Contains(err.Error(), "invalid cache `soon` for env `INVALID`") {
+		t.Errorf("expected invalid cache error, got %v", err)
+	}
+}
+
+func TestEnv_LazyProjectEnv(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"mani.yaml": `
+projects:
+  api:
+    env:
+      OK: $(echo ok)
+  web:
+    env:
+      FAIL: $(exit 1)
+`,
+	})
+
+	// Project env is not evaluated when the config is read
+	config, err := ReadConfig(filepath.Join(dir, "mani.yaml"), "", "", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	projects, _ := config.GetProjectsByName([]string{"api"})
+	err = ParseProjectsEnv(projects)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if projects[0].EnvList[0] != "OK=ok\n" {
+		t.Errorf("expected evaluated env, got %q", projects[0].EnvList)
+	}
+
+	if err := ParseProjectsEnv(config.ProjectList); err == nil {
+		t.Errorf("expected evaluating web env to fail")
+	}
+}
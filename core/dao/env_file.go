@@ -0,0 +1,204 @@
+package dao
+
+import (
+	"bufio"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/alajmo/mani/core"
+)
+
+var (
+	envKeyRegex      = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+	envVariableRegex = regexp.MustCompile(`\$\{([a-zA-Z_][a-zA-Z0-9_]*)\}`)
+)
+
+// EnvFile is a dotenv file whose variables are added to the env of the config,
+// a project or a task. Variables declared in env take precedence over env files,
+// and later env files take precedence over earlier ones.
+type EnvFile struct {
+	Path     string `yaml:"path"`
+	Required *bool  `yaml:"required"` // defaults to true, a missing optional file is skipped
+
+	EnvList []string `yaml:"-"` // variables in the file
+	Found   bool     `yaml:"-"`
+}
+
+func (e EnvFile) IsRequired() bool {
+	return e.Required == nil || *e.Required
+}
+
+// Keys returns the names of the variables in the file
+func (e EnvFile) Keys() []string {
+	keys := make([]string, len(e.EnvList))
+	for i, env := range e.EnvList {
+		keys[i], _, _ = strings.Cut(env, "=")
+	}
+
+	return keys
+}
+
+// ParseEnvFiles reads the env files declared in node, which is either a single
+// path or a list of paths and mappings with path and required. Relative paths
+// are resolved from dir. `${VAR}` in values is replaced with variables declared
+// earlier in the files, or the variable in the environment mani runs in.
+func ParseEnvFiles(node yaml.Node, dir string) ([]EnvFile, []error) {
+	var entries []*yaml.Node
+	switch node.Kind {
+	case 0:
+		return nil, nil
+	case yaml.ScalarNode:
+		entries = []*yaml.Node{&node}
+	default:
+		entries = node.Content
+	}
+
+	var envFiles []EnvFile
+	var errs []error
+	var envs []string
+	for _, entry := range entries {
+		var envFile EnvFile
+		if entry.Kind == yaml.ScalarNode {
+			envFile.Path = entry.Value
+		} else {
+			err := entry.Decode(&envFile)
+			if err != nil {
+				errs = append(errs, overlayErrors(err)...)
+				continue
+			}
+		}
+
+		if envFile.Path == "" {
+			errs = append(errs, &core.MissingEnvFilePath{})
+			continue
+		}
+
+		path, err := core.GetAbsolutePath(dir, envFile.Path, "")
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		envFile.Path = path
+
+		dat, err := os.ReadFile(path)
+		if os.IsNotExist(err) && !envFile.IsRequired() {
+			envFiles = append(envFiles, envFile)
+			continue
+		}
+		if os.IsNotExist(err) {
+			errs = append(errs, &core.EnvFileNotFound{Path: path})
+			continue
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		envFile.EnvList, err = parseDotenv(path, string(dat), envs)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		envFile.Found = true
+		envs = MergeEnvs(envFile.EnvList, envs)
+
+		envFiles = append(envFiles, envFile)
+	}
+
+	return envFiles, errs
+}
+
+// EnvFilesEnv returns the variables of the env files, later files take
+// precedence over earlier ones
+func EnvFilesEnv(envFiles []EnvFile) []string {
+	var envs []string
+	for _, envFile := range slices.Backward(envFiles) {
+		envs = MergeEnvs(envs, envFile.EnvList)
+	}
+
+	return envs
+}
+
+// parseDotenv parses the lines of a dotenv file in the form KEY=value, with
+// optional `export` prefix, quotes and comments. Variables in double quoted and
+// unquoted values are interpolated, single quoted values are kept as is.
+func parseDotenv(path string, content string, parentEnv []string) ([]string, error) {
+	var envs []string
+
+	lookup := func(name string) string {
+		for _, env := range slices.Concat(envs, parentEnv) {
+			if key, value, _ := strings.Cut(env, "="); key == name {
+				return value
+			}
+		}
+		return os.Getenv(name)
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		text = strings.TrimPrefix(text, "export ")
+
+		key, value, found := strings.Cut(text, "=")
+		key = strings.TrimSpace(key)
+		if !found || !envKeyRegex.MatchString(key) {
+			return nil, &core.EnvFileInvalid{Path: path, Line: line, Reason: "expected KEY=value"}
+		}
+		value = strings.TrimSpace(value)
+
+		switch {
+		case strings.HasPrefix(value, "'"):
+			end := strings.Index(value[1:], "'")
+			if end < 0 {
+				return nil, &core.EnvFileInvalid{Path: path, Line: line, Reason: "missing closing quote"}
+			}
+			value = value[1 : end+1]
+		case strings.HasPrefix(value, `"`):
+			end := closingQuote(value[1:])
+			if end < 0 {
+				return nil, &core.EnvFileInvalid{Path: path, Line: line, Reason: "missing closing quote"}
+			}
+			value = strings.NewReplacer(`\n`, "\n", `\"`, `"`, `\\`, `\`).Replace(value[1 : end+1])
+			value = interpolateEnv(value, lookup)
+		default:
+			if i := strings.Index(value, " #"); i >= 0 {
+				value = strings.TrimSpace(value[:i])
+			}
+			value = interpolateEnv(value, lookup)
+		}
+
+		// Variables declared later in the file take precedence
+		envs = slices.DeleteFunc(envs, func(env string) bool { return strings.HasPrefix(env, key+"=") })
+		envs = append(envs, key+"="+value)
+	}
+
+	return envs, nil
+}
+
+// closingQuote returns the index of the first unescaped double quote, or -1
+func closingQuote(s string) int {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+
+	return -1
+}
+
+// interpolateEnv replaces `${VAR}` with the value returned by lookup
+func interpolateEnv(value string, lookup func(string) string) string {
+	return envVariableRegex.ReplaceAllStringFunc(value, func(match string) string {
+		return lookup(envVariableRegex.FindStringSubmatch(match)[1])
+	})
+}
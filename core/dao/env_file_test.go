@@ -0,0 +1,167 @@
+package dao
+
+import (
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestEnvFile_ParseDotenv(t *testing.T) {
+	t.Setenv("MANI_TEST_HOME", "/home/mani")
+
+	content := `
+# comment
+export A=1
+B = two words # trailing comment
+C="quoted ${A} \"x\""
+D='single ${A}'
+E=${MANI_TEST_HOME}/bin:${C}
+A=3
+`
+	envs, err := parseDotenv(".env", content, []string{"P=parent"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"B=two words",
+		`C=quoted 1 "x"`,
+		"D=single ${A}",
+		`E=/home/mani/bin:quoted 1 "x"`,
+		"A=3",
+	}
+	if !reflect.DeepEqual(envs, expected) {
+		t.Errorf("expected %v, got %v", expected, envs)
+	}
+
+	_, err = parseDotenv(".env", "A=1\nnot valid\n", nil)
+	if err == nil || err.Error() != "invalid env file `.env:2`, expected KEY=value" {
+		t.Errorf("expected invalid line error, got %v", err)
+	}
+}
+
+func TestEnvFile_Precedence(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"mani.yaml": `
+env_files:
+  - .env
+  - path: .env.local
+    required: false
+
+env:
+  INLINE: config
+
+projects:
+  api:
+    env_files: .env
+    env:
+      INLINE: project
+
+tasks:
+  build:
+    env_files: [build.env]
+    cmd: make
+`,
+		".env":      "INLINE=file\nSHARED=config\nBASE=base\n",
+		"build.env": "SHARED=${BASE}-task\n",
+		"api/.env":  "API=1\n",
+	})
+
+	config, err := ReadConfig(filepath.Join(dir, "mani.yaml"), "", "", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(config.EnvFileList) != 2 || config.EnvFileList[1].Found {
+		t.Errorf("expected optional .env.local to be skipped, got %+v", config.EnvFileList)
+	}
+	configEnv := MergeEnvs(config.EnvList, EnvFilesEnv(config.EnvFileList))
+	if !reflect.DeepEqual(configEnv, []string{"INLINE=config", "SHARED=config", "BASE=base"}) {
+		t.Errorf("unexpected config env %v", configEnv)
+	}
+
+	// Project env files are read when the project is used
+	project, _ := config.GetProject("api")
+	projects := []Project{*project}
+	if err := ParseProjectsEnv(projects); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(projects[0].EnvList, []string{"INLINE=project", "API=1"}) {
+		t.Errorf("unexpected project env %v", projects[0].EnvList)
+	}
+
+	// Interpolation is scoped to the env files of the same level
+	task, _ := config.GetTask("build")
+	if !reflect.DeepEqual(EnvFilesEnv(task.EnvFileList), []string{"SHARED=-task"}) {
+		t.Errorf("unexpected task env %v", EnvFilesEnv(task.EnvFileList))
+	}
+
+	writeTestFiles(t, dir, map[string]string{
+		"mani.yaml": "env_files: [missing.env]\n",
+	})
+	_, err = ReadConfig(filepath.Join(dir, "mani.yaml"), "", "", false)
+	if err == nil || !strings.Contains(err.Error(), "cannot find env file") {
+		t.Errorf("expected missing env file error, got %v", err)
+	}
+}
+
+func TestEnvFile_ProjectNotCloned(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"mani.yaml": `
+projects:
+  lib:
+    url: git@example.com:lib.git
+    env_files: [.env]
+    env:
+      NAME: lib
+`,
+	})
+
+	config, err := ReadConfig(filepath.Join(dir, "mani.yaml"), "", "", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Env files are skipped for projects which are not cloned
+	projects := slices.Clone(config.ProjectList)
+	if err := ParseProjectsEnv(projects); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(projects[0].EnvList, []string{"NAME=lib"}) {
+		t.Errorf("unexpected project env %v", projects[0].EnvList)
+	}
+
+	// Required env files are missing once the project is cloned
+	writeTestFiles(t, dir, map[string]string{"lib/README": ""})
+	projects = slices.Clone(config.ProjectList)
+	err = ParseProjectsEnv(projects)
+	if err == nil || !strings.Contains(err.Error(), "cannot find env file") {
+		t.Errorf("expected missing env file error, got %v", err)
+	}
+}
+
+func TestEnvFile_DescribeConfig(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"mani.yaml": "projects:\n  a:\n    env_files: [.env]\n",
+		"a/.env":    "",
+	})
+
+	config, err := ReadConfig(filepath.Join(dir, "mani.yaml"), "", "", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// A project without other fields or env still shows its env files
+	out, err := config.DescribeConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out, "  a:\n    env_files:") {
+		t.Errorf("expected project env files, got:\n%s", out)
+	}
+}
@@ -0,0 +1,261 @@
+package dao
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/alajmo/mani/core"
+)
+
+var EXPORT_FORMATS = []string{"repos", "repo-xml", "json", "yaml", "csv"}
+
+// ExportProjects serializes projects to the given format. Project paths are
+// relative to the config directory.
+func ExportProjects(format string, projects []Project) (string, error) {
+	switch format {
+	case "repos":
+		return exportVcstool(projects)
+	case "repo-xml":
+		return exportRepoXML(projects)
+	case "json":
+		return exportJSON(projects)
+	case "yaml":
+		return exportYAML(projects)
+	case "csv":
+		return exportCSV(projects)
+	default:
+		return "", &core.ExportFormatInvalid{Format: format, Formats: EXPORT_FORMATS}
+	}
+}
+
+// exportVcstool returns a vcstool .repos file, projects without url are omitted
+func exportVcstool(projects []Project) (string, error) {
+	repos := &yaml.Node{Kind: yaml.MappingNode}
+	for _, p := range projects {
+		if p.URL == "" {
+			continue
+		}
+
+		repo := &yaml.Node{Kind: yaml.MappingNode}
+		repo.Content = append(repo.Content, ScalarNode("type"), ScalarNode("git"))
+		repo.Content = append(repo.Content, ScalarNode("url"), ScalarNode(p.URL))
+		if p.Branch != "" {
+			repo.Content = append(repo.Content, ScalarNode("version"), ScalarNode(p.Branch))
+		}
+		repos.Content = append(repos.Content, ScalarNode(p.RelPath), repo)
+	}
+
+	root := &yaml.Node{Kind: yaml.MappingNode}
+	root.Content = append(root.Content, ScalarNode("repositories"), repos)
+
+	return EncodeYAML(root)
+}
+
+type repoXMLManifest struct {
+	XMLName  xml.Name         `xml:"manifest"`
+	Remotes  []repoXMLRemote  `xml:"remote"`
+	Projects []repoXMLProject `xml:"project"`
+}
+
+type repoXMLRemote struct {
+	Name  string `xml:"name,attr"`
+	Fetch string `xml:"fetch,attr"`
+}
+
+type repoXMLProject struct {
+	Name     string `xml:"name,attr"`
+	Path     string `xml:"path,attr"`
+	Remote   string `xml:"remote,attr"`
+	Revision string `xml:"revision,attr,omitempty"`
+	Groups   string `xml:"groups,attr,omitempty"`
+}
+
+// exportRepoXML returns a Google repo XML manifest. A remote is added for each
+// host, projects without url are omitted.
+func exportRepoXML(projects []Project) (string, error) {
+	manifest := repoXMLManifest{}
+	remotes := make(map[string]string)
+	remoteNames := make(map[string]bool)
+
+	for _, p := range projects {
+		if p.URL == "" {
+			continue
+		}
+
+		fetch, name := splitRepoURL(p.URL)
+		remote, found := remotes[fetch]
+		if !found {
+			remote = remoteName(fetch)
+			for i := 2; remoteNames[remote]; i++ {
+				remote = fmt.Sprintf("%s-%d", remoteName(fetch), i)
+			}
+			remoteNames[remote] = true
+			remotes[fetch] = remote
+			manifest.Remotes = append(manifest.Remotes, repoXMLRemote{Name: remote, Fetch: fetch})
+		}
+
+		manifest.Projects = append(manifest.Projects, repoXMLProject{
+			Name:     name,
+			Path:     p.RelPath,
+			Remote:   remote,
+			Revision: p.Branch,
+			Groups:   strings.Join(p.Tags, ","),
+		})
+	}
+
+	out, err := xml.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	return xml.Header + string(out) + "\n", nil
+}
+
+// splitRepoURL splits a git url into the fetch url of the host and the
+// repository name, scp-like urls are converted to ssh urls
+func splitRepoURL(rawURL string) (string, string) {
+	if !strings.Contains(rawURL, "://") {
+		if host, path, found := strings.Cut(rawURL, ":"); found && !strings.Contains(host, "/") {
+			return "ssh://" + host, strings.TrimPrefix(path, "/")
+		}
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return rawURL[:strings.LastIndex(rawURL, "/")+1], rawURL[strings.LastIndex(rawURL, "/")+1:]
+	}
+
+	fetch := fmt.Sprintf("%s://%s", u.Scheme, u.Host)
+	if u.User != nil {
+		fetch = fmt.Sprintf("%s://%s@%s", u.Scheme, u.User.String(), u.Host)
+	}
+
+	return fetch, strings.TrimPrefix(u.Path, "/")
+}
+
+func remoteName(fetch string) string {
+	host := fetch
+	if i := strings.Index(host, "://"); i >= 0 {
+		host = host[i+3:]
+	}
+	if i := strings.Index(host, "@"); i >= 0 {
+		host = host[i+1:]
+	}
+	host = strings.Trim(host, "/")
+
+	if host == "" {
+		return "origin"
+	}
+
+	return host
+}
+
+type exportProject struct {
+	Name      string           `json:"name"`
+	Path      string           `json:"path"`
+	Desc      string           `json:"desc,omitempty"`
+	URL       string           `json:"url,omitempty"`
+	Branch    string           `json:"branch,omitempty"`
+	Tags      []string         `json:"tags,omitempty"`
+	Remotes   []exportRemote   `json:"remotes,omitempty"`
+	Worktrees []exportWorktree `json:"worktrees,omitempty"`
+}
+
+type exportRemote struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+type exportWorktree struct {
+	Path   string `json:"path"`
+	Branch string `json:"branch,omitempty"`
+}
+
+func exportJSON(projects []Project) (string, error) {
+	data := []exportProject{}
+	for _, p := range projects {
+		project := exportProject{
+			Name:   p.Name,
+			Path:   p.RelPath,
+			Desc:   p.Desc,
+			URL:    p.URL,
+			Branch: p.Branch,
+			Tags:   p.Tags,
+		}
+		for _, remote := range p.RemoteList {
+			project.Remotes = append(project.Remotes, exportRemote(remote))
+		}
+		for _, wt := range p.WorktreeList {
+			project.Worktrees = append(project.Worktrees, exportWorktree(wt))
+		}
+		data = append(data, project)
+	}
+
+	out, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	return string(out) + "\n", nil
+}
+
+// exportYAML returns the projects section of a mani config
+func exportYAML(projects []Project) (string, error) {
+	section := &yaml.Node{Kind: yaml.MappingNode}
+	for _, p := range projects {
+		p.Path = p.RelPath
+		section.Content = append(section.Content, ScalarNode(p.Name), ProjectNode(p))
+	}
+
+	root := &yaml.Node{Kind: yaml.MappingNode}
+	root.Content = append(root.Content, ScalarNode("projects"), section)
+
+	return EncodeYAML(root)
+}
+
+// exportCSV returns one row per project, list values are separated by spaces
+func exportCSV(projects []Project) (string, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	err := writer.Write([]string{"name", "path", "desc", "url", "branch", "tags", "remotes", "worktrees"})
+	if err != nil {
+		return "", err
+	}
+
+	for _, p := range projects {
+		var remotes []string
+		for _, remote := range p.RemoteList {
+			remotes = append(remotes, fmt.Sprintf("%s=%s", remote.Name, remote.URL))
+		}
+
+		var worktrees []string
+		for _, wt := range p.WorktreeList {
+			worktrees = append(worktrees, fmt.Sprintf("%s:%s", wt.Path, wt.Branch))
+		}
+
+		err := writer.Write([]string{
+			p.Name,
+			p.RelPath,
+			p.Desc,
+			p.URL,
+			p.Branch,
+			strings.Join(p.Tags, " "),
+			strings.Join(remotes, " "),
+			strings.Join(worktrees, " "),
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+
+	writer.Flush()
+	return buf.String(), writer.Error()
+}
@@ -0,0 +1,59 @@
+package dao
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExport_RoundTrip(t *testing.T) {
+	projects := []Project{
+		{Name: "one", RelPath: "src/one", URL: "https://example.com/x/one.git", Branch: "dev", Tags: []string{"a", "b"}},
+		{Name: "two", RelPath: "two", URL: "git@example.com:x/two.git"},
+		{Name: "local", RelPath: "local"},
+	}
+
+	tests := []struct {
+		format   string
+		parse    func([]byte) ([]Project, error)
+		expected []Project
+	}{
+		{
+			format: "repos",
+			parse:  parseVcstoolManifest,
+			expected: []Project{
+				{Path: "src/one", URL: "https://example.com/x/one.git", Branch: "dev"},
+				{Path: "two", URL: "git@example.com:x/two.git"},
+			},
+		},
+		{
+			format: "repo-xml",
+			parse:  func(dat []byte) ([]Project, error) { return parseRepoManifest(dat, "") },
+			expected: []Project{
+				{Path: "src/one", URL: "https://example.com/x/one.git", Branch: "dev", Tags: []string{"a", "b"}},
+				{Path: "two", URL: "ssh://git@example.com/x/two.git"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			out, err := ExportProjects(tt.format, projects)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			parsed, err := tt.parse([]byte(out))
+			if err != nil {
+				t.Fatalf("failed to parse exported manifest: %v\n%s", err, out)
+			}
+
+			if !reflect.DeepEqual(parsed, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, parsed)
+			}
+		})
+	}
+
+	if _, err := ExportProjects("toml", projects); err == nil {
+		t.Errorf("expected error for invalid format")
+	}
+}
@@ -0,0 +1,254 @@
+package dao
+
+import (
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/alajmo/mani/core"
+)
+
+// Canonical key order of the resources, keys not listed keep their order after
+// the listed keys
+var (
+	configKeyOrder = []string{
+		"version", "import", "shell", "sync_remotes", "sync_gitignore", "remove_orphaned_worktrees",
+		"reload_tui_on_change", "project_tasks", "project_templates", "projects", "specs",
+		"targets", "env_files", "env", "secrets", "profiles", "tasks", "themes",
+	}
+	projectKeyOrder = []string{
+		"extends", "desc", "path", "url", "clone", "branch", "single_branch", "sync", "tags",
+		"remotes", "worktrees", "env_files", "env", "tasks",
+	}
+	taskKeyOrder = []string{
+		"extends", "desc", "theme", "shell", "tty", "env_files", "env", "spec", "target", "cmd", "commands",
+	}
+	commandKeyOrder = []string{"name", "desc", "shell", "tty", "env", "task", "cmd"}
+	specKeyOrder    = []string{
+		"output", "parallel", "forks", "ignore_errors", "ignore_non_existing",
+		"omit_empty_rows", "omit_empty_columns", "clear_output",
+	}
+	targetKeyOrder   = []string{"all", "cwd", "projects", "paths", "tags", "tags_expr"}
+	themeKeyOrder    = []string{"color", "stream", "table", "tree", "block", "tui"}
+	profileKeyOrder  = []string{"env", "specs", "targets", "projects"}
+	secretKeyOrder   = []string{"command", "file", "env", "keyring"}
+	worktreeKeyOrder = []string{"path", "branch"}
+	envFileKeyOrder  = []string{"path", "required"}
+	importKeyOrder   = []string{"path", "as"}
+)
+
+// ConfigFiles returns the files of the config which mani can rewrite, the main
+// config and its local imports written in YAML
+func (c Config) ConfigFiles() []string {
+	cacheDir, _ := GetImportCacheDir()
+
+	var files []string
+	for _, path := range append([]string{c.Path}, c.ConfigPaths...) {
+		if !isYAMLFile(path) || slices.Contains(files, path) || (c.UserConfigFile != nil && path == *c.UserConfigFile) {
+			continue
+		}
+		if cacheDir != "" && strings.HasPrefix(path, cacheDir+string(filepath.Separator)) {
+			continue
+		}
+		files = append(files, path)
+	}
+
+	return files
+}
+
+// Format returns the file in canonical form: 2 space indentation and keys in
+// the order of the documented config. Comments and blank lines between entries
+// are kept. If sortProjects is set, projects are sorted by name. Entries are not
+// moved in front of the anchors they reference.
+func (f *YAMLFile) Format(sortProjects bool) ([]byte, error) {
+	// File without content, such as a file with only comments
+	if len(f.Doc.Content) == 0 || f.Doc.Content[0].Kind != yaml.MappingNode || len(f.Doc.Content[0].Content) == 0 {
+		return f.src, nil
+	}
+
+	root := f.Root()
+	first := root.Content[0]
+	f.sortKeys(root, configKeyOrder)
+
+	f.keepHeadComment(first)
+
+	for _, key := range []string{"projects", "project_templates"} {
+		projects := MappingValue(root, key)
+		if sortProjects && key == "projects" {
+			f.sortEntries(projects)
+		}
+		formatEntries(projects, f.formatProject)
+	}
+	formatEntries(MappingValue(root, "tasks"), f.formatTask)
+	formatEntries(MappingValue(root, "specs"), func(node *yaml.Node) { f.sortKeys(node, specKeyOrder) })
+	formatEntries(MappingValue(root, "targets"), func(node *yaml.Node) { f.sortKeys(node, targetKeyOrder) })
+	formatEntries(MappingValue(root, "themes"), func(node *yaml.Node) { f.sortKeys(node, themeKeyOrder) })
+	formatEntries(MappingValue(root, "secrets"), func(node *yaml.Node) { f.sortKeys(node, secretKeyOrder) })
+	formatEntries(MappingValue(root, "profiles"), f.formatProfile)
+	f.formatItems(MappingValue(root, "import"), importKeyOrder)
+	f.formatItems(MappingValue(root, "env_files"), envFileKeyOrder)
+
+	out, err := f.marshal(2)
+	if err != nil {
+		return nil, err
+	}
+
+	// Make sure the formatted file can be read
+	var doc yaml.Node
+	err = yaml.Unmarshal(out, &doc)
+	if err != nil {
+		return nil, &core.FormatFailed{Path: f.Path, Reason: err.Error()}
+	}
+
+	return out, nil
+}
+
+// keepHeadComment keeps the comment at the top of the file at the top when the
+// first key is no longer first
+func (f *YAMLFile) keepHeadComment(first *yaml.Node) {
+	root := f.Root()
+	if root.Content[0] != first && first.HeadComment != "" && f.Doc.HeadComment == "" {
+		f.Doc.HeadComment = first.HeadComment
+		first.HeadComment = ""
+	}
+}
+
+func (f *YAMLFile) formatProject(node *yaml.Node) {
+	f.sortKeys(node, projectKeyOrder)
+	f.formatItems(MappingValue(node, "worktrees"), worktreeKeyOrder)
+	f.formatItems(MappingValue(node, "env_files"), envFileKeyOrder)
+	formatEntries(MappingValue(node, "tasks"), f.formatTask)
+}
+
+func (f *YAMLFile) formatTask(node *yaml.Node) {
+	f.sortKeys(node, taskKeyOrder)
+	f.formatItems(MappingValue(node, "commands"), commandKeyOrder)
+	f.formatItems(MappingValue(node, "env_files"), envFileKeyOrder)
+	f.sortKeys(MappingValue(node, "spec"), specKeyOrder)
+	f.sortKeys(MappingValue(node, "target"), targetKeyOrder)
+	f.sortKeys(MappingValue(node, "theme"), themeKeyOrder)
+}
+
+func (f *YAMLFile) formatProfile(node *yaml.Node) {
+	f.sortKeys(node, profileKeyOrder)
+	formatEntries(MappingValue(node, "specs"), func(node *yaml.Node) { f.sortKeys(node, specKeyOrder) })
+	formatEntries(MappingValue(node, "targets"), func(node *yaml.Node) { f.sortKeys(node, targetKeyOrder) })
+	formatEntries(MappingValue(node, "projects"), f.formatProject)
+}
+
+// formatEntries calls format with the value of each entry of a mapping
+func formatEntries(node *yaml.Node, format func(node *yaml.Node)) {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return
+	}
+
+	for i := 1; i < len(node.Content); i += 2 {
+		format(node.Content[i])
+	}
+}
+
+// formatItems sorts the keys of the mapping items of a sequence
+func (f *YAMLFile) formatItems(node *yaml.Node, order []string) {
+	if node == nil || node.Kind != yaml.SequenceNode {
+		return
+	}
+
+	for _, item := range node.Content {
+		f.sortKeys(item, order)
+	}
+}
+
+// sortKeys orders the keys of a mapping node, keys not in order are kept in
+// their current order after the ordered keys
+func (f *YAMLFile) sortKeys(node *yaml.Node, order []string) {
+	rank := func(key string) int {
+		if i := slices.Index(order, key); i >= 0 {
+			return i
+		}
+		return len(order)
+	}
+
+	f.reorder(node, func(a, b *yaml.Node) bool { return rank(a.Value) < rank(b.Value) })
+}
+
+// sortEntries sorts the entries of a mapping node by key
+func (f *YAMLFile) sortEntries(node *yaml.Node) {
+	f.reorder(node, func(a, b *yaml.Node) bool { return a.Value < b.Value })
+}
+
+// reorder sorts the entries of a mapping node by key. The entries keep their
+// order if sorting would move an alias in front of its anchor. When the first
+// entry is moved and other entries are preceded by a blank line, the first
+// entry is preceded by a blank line as well.
+func (f *YAMLFile) reorder(node *yaml.Node, less func(a, b *yaml.Node) bool) {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return
+	}
+
+	pairs := mappingPairs(node)
+	sorted := slices.Clone(pairs)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return less(sorted[i][0], sorted[j][0])
+	})
+
+	if slices.Equal(pairs, sorted) || !anchorsBeforeAliases(sorted) {
+		return
+	}
+
+	if slices.ContainsFunc(pairs[1:], func(pair [2]*yaml.Node) bool { return f.isBlank(pair[0]) }) {
+		f.spaced[pairs[0][0]] = true
+	}
+
+	setMappingPairs(node, sorted)
+}
+
+// anchorsBeforeAliases returns false if an entry references an anchor declared
+// in an entry after it
+func anchorsBeforeAliases(pairs [][2]*yaml.Node) bool {
+	anchors := make(map[string]int)
+	for i, pair := range pairs {
+		walkNodes(pair[1], func(n *yaml.Node) {
+			if n.Anchor != "" {
+				anchors[n.Anchor] = i
+			}
+		})
+	}
+
+	valid := true
+	for i, pair := range pairs {
+		walkNodes(pair[1], func(n *yaml.Node) {
+			if j, found := anchors[n.Value]; n.Kind == yaml.AliasNode && found && j > i {
+				valid = false
+			}
+		})
+	}
+
+	return valid
+}
+
+// walkNodes calls fn with the node and all nodes below it, aliases are not followed
+func walkNodes(node *yaml.Node, fn func(n *yaml.Node)) {
+	fn(node)
+	for _, child := range node.Content {
+		walkNodes(child, fn)
+	}
+}
+
+func mappingPairs(node *yaml.Node) [][2]*yaml.Node {
+	var pairs [][2]*yaml.Node
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		pairs = append(pairs, [2]*yaml.Node{node.Content[i], node.Content[i+1]})
+	}
+
+	return pairs
+}
+
+func setMappingPairs(node *yaml.Node, pairs [][2]*yaml.Node) {
+	node.Content = node.Content[:0]
+	for _, pair := range pairs {
+		node.Content = append(node.Content, pair[0], pair[1])
+	}
+}
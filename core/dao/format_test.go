@@ -0,0 +1,148 @@
+package dao
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestYAMLFile_Format(t *testing.T) {
+	src := `# Main config
+tasks:
+    build:
+        cmd: make # build all
+        desc: Build
+
+    lint: make lint
+
+projects:
+    web:
+        url: git@example.com:web.git
+        path: web
+    # The api
+    api:
+        tags: [core]
+        desc: API
+import:
+    - team.yaml
+`
+
+	tests := []struct {
+		name         string
+		sortProjects bool
+		expected     string
+	}{
+		{
+			name: "key order",
+			expected: `# Main config
+
+import:
+  - team.yaml
+
+projects:
+  web:
+    path: web
+    url: git@example.com:web.git
+  # The api
+  api:
+    desc: API
+    tags: [core]
+
+tasks:
+  build:
+    desc: Build
+    cmd: make # build all
+
+  lint: make lint
+`,
+		},
+		{
+			name:         "sorted projects",
+			sortProjects: true,
+			expected: `# Main config
+
+import:
+  - team.yaml
+
+projects:
+  # The api
+  api:
+    desc: API
+    tags: [core]
+  web:
+    path: web
+    url: git@example.com:web.git
+
+tasks:
+  build:
+    desc: Build
+    cmd: make # build all
+
+  lint: make lint
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeTestFiles(t, dir, map[string]string{"mani.yaml": src})
+
+			f, err := ReadYAMLFile(filepath.Join(dir, "mani.yaml"))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			out, err := f.Format(tt.sortProjects)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(out) != tt.expected {
+				t.Fatalf("expected:\n%s\ngot:\n%s", tt.expected, out)
+			}
+
+			// Formatting is idempotent
+			writeTestFiles(t, dir, map[string]string{"mani.yaml": string(out)})
+			f, err = ReadYAMLFile(filepath.Join(dir, "mani.yaml"))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			again, err := f.Format(tt.sortProjects)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(again) != string(out) {
+				t.Fatalf("expected formatting to be idempotent, got:\n%s", again)
+			}
+		})
+	}
+}
+
+func TestYAMLFile_FormatAnchors(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"mani.yaml": `env: &common
+  BRANCH: main
+
+projects:
+  api:
+    env: *common
+`})
+
+	f, err := ReadYAMLFile(filepath.Join(dir, "mani.yaml"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Projects are not moved in front of the anchor they reference
+	out, err := f.Format(false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(out) != string(f.src) {
+		t.Fatalf("expected config to be unchanged, got:\n%s", out)
+	}
+
+	writeTestFiles(t, dir, map[string]string{"mani.yaml": string(out)})
+	if _, err := ReadConfig(filepath.Join(dir, "mani.yaml"), "", "", false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
 
 	"github.com/alajmo/mani/core"
 	"github.com/gookit/color"
@@ -11,7 +14,8 @@ import (
 )
 
 type Import struct {
-	Path string
+	Path      string
+	Namespace string
 
 	context     string
 	contextLine int
@@ -39,6 +43,49 @@ func (c *Config) GetImportList() ([]Import, []ResourceErrors[Import]) {
 			contextLine: c.Import.Content[i].Line,
 		}
 
+		// Namespaced import: { path: tasks.yaml, as: team-a }
+		if c.Import.Content[i].Kind == yaml.MappingNode {
+			var value struct {
+				Path string `yaml:"path"`
+				As   string `yaml:"as"`
+			}
+			err := c.Import.Content[i].Decode(&value)
+			if err != nil {
+				foundErrors = true
+				importError := ResourceErrors[Import]{Resource: imp, Errors: []error{err}}
+				if typeErr, ok := err.(*yaml.TypeError); ok {
+					importError.Errors = core.StringsToErrors(typeErr.Errors)
+				}
+				importErrors = append(importErrors, importError)
+				continue
+			}
+
+			if value.Path == "" {
+				foundErrors = true
+				importError := ResourceErrors[Import]{Resource: imp, Errors: []error{&core.MissingImportPath{}}}
+				importErrors = append(importErrors, importError)
+				continue
+			}
+
+			if value.As != "" && !namespaceRegex.MatchString(value.As) {
+				foundErrors = true
+				importError := ResourceErrors[Import]{Resource: imp, Errors: []error{&core.InvalidImportNamespace{Namespace: value.As}}}
+				importErrors = append(importErrors, importError)
+				continue
+			}
+
+			imp.Path = value.Path
+			imp.Namespace = value.As
+		}
+
+		path, err := c.interpolate(imp.Path, true)
+		if err != nil {
+			foundErrors = true
+			importErrors = append(importErrors, ResourceErrors[Import]{Resource: imp, Errors: []error{err}})
+			continue
+		}
+		imp.Path = path
+
 		imports = append(imports, *imp)
 	}
 
@@ -58,7 +105,21 @@ type ConfigResources struct {
 	Tasks    []Task
 	Projects []Project
 	Envs     []string
+	EnvCache EnvCache
+	EnvFiles []EnvFile
+
+	// Projects are parsed once all configs are loaded, so they can extend
+	// the project templates of any config
+	ProjectTemplates yaml.Node
+	projectConfigs   []projectConfig
+
+	// The env of the active profile takes precedence in ${VAR} of all configs
+	profileEnv      []string
+	profileEnvCache EnvCache
+
+	Deprecations []Lint
 
+	ConfigErrors  []ResourceErrors[Config]
 	ThemeErrors   []ResourceErrors[Theme]
 	SpecErrors    []ResourceErrors[Spec]
 	TargetErrors  []ResourceErrors[Target]
@@ -67,11 +128,17 @@ type ConfigResources struct {
 	ImportErrors  []ResourceErrors[Import]
 }
 
+type projectConfig struct {
+	config    Config
+	namespace string
+}
+
 type Node struct {
-	Path     string
-	Imports  []Import
-	Visiting bool
-	Visited  bool
+	Path      string
+	Namespace string
+	Imports   []Import
+	Visiting  bool
+	Visited   bool
 }
 
 type NodeLink struct {
@@ -104,10 +171,15 @@ func (c *FoundCyclicDependency) Error() string {
 //
 // This is the first parsing, later on we will perform more passes where we check what commands/tasks
 // are imported.
-func (c Config) importConfigs() (ConfigResources, error) {
+func (c Config) importConfigs(profile *Profile) (ConfigResources, error) {
 	// Main config
 	ci := ConfigResources{}
-	c.loadResources(&ci)
+	if profile != nil {
+		// Cache errors are reported when the profile is applied
+		ci.profileEnv = ParseNodeEnv(profile.Env)
+		ci.profileEnvCache, _ = ParseEnvCache(profile.Env)
+	}
+	c.loadResources(&ci, "")
 
 	if c.UserConfigFile != nil {
 		ci.Imports = append(ci.Imports, Import{Path: *c.UserConfigFile, context: c.Path, contextLine: -1})
@@ -124,6 +196,30 @@ func (c Config) importConfigs() (ConfigResources, error) {
 
 	dfs(&n, m, &cycles, &ci)
 
+	ci.loadProjects()
+
+	// Resources of the user config are overridden by the resources with the
+	// same name in the other configs
+	if c.UserConfigFile != nil {
+		userConfig, err := filepath.Abs(*c.UserConfigFile)
+		if err != nil {
+			return ci, err
+		}
+
+		ci.Projects = dropOverridden(ci.Projects, userConfig, func(p *Project) string { return p.Name })
+		ci.Tasks = dropOverridden(ci.Tasks, userConfig, func(t *Task) string { return t.Name })
+		ci.Themes = dropOverridden(ci.Themes, userConfig, func(t *Theme) string { return t.Name })
+		ci.Specs = dropOverridden(ci.Specs, userConfig, func(s *Spec) string { return s.Name })
+		ci.Targets = dropOverridden(ci.Targets, userConfig, func(t *Target) string { return t.Name })
+	}
+
+	// Resources with the same name in different configs
+	ci.ProjectErrors = append(ci.ProjectErrors, findDuplicates("project", ci.Projects, func(p *Project) string { return p.Name })...)
+	ci.TaskErrors = append(ci.TaskErrors, findDuplicates("task", ci.Tasks, func(t *Task) string { return t.Name })...)
+	ci.ThemeErrors = append(ci.ThemeErrors, findDuplicates("theme", ci.Themes, func(t *Theme) string { return t.Name })...)
+	ci.SpecErrors = append(ci.SpecErrors, findDuplicates("spec", ci.Specs, func(s *Spec) string { return s.Name })...)
+	ci.TargetErrors = append(ci.TargetErrors, findDuplicates("target", ci.Targets, func(t *Target) string { return t.Name })...)
+
 	// Get errors
 	configErr := concatErrors(ci, &cycles)
 
@@ -134,6 +230,55 @@ func (c Config) importConfigs() (ConfigResources, error) {
 	return ci, nil
 }
 
+// findDuplicates returns an error for each resource with the same name as a
+// resource declared before it
+func findDuplicates[T any, PT interface {
+	*T
+	Resource
+}](kind string, resources []T, getName func(*T) string) []ResourceErrors[T] {
+	var errs []ResourceErrors[T]
+	seen := make(map[string]PT)
+
+	for i := range resources {
+		name := getName(&resources[i])
+		first, found := seen[name]
+		if !found {
+			seen[name] = PT(&resources[i])
+			continue
+		}
+
+		errs = append(errs, ResourceErrors[T]{
+			Resource: &resources[i],
+			Errors: []error{&core.DuplicateResource{
+				Kind:    kind,
+				Name:    name,
+				Context: first.GetContext(),
+				Line:    first.GetContextLine(),
+			}},
+		})
+	}
+
+	return errs
+}
+
+// dropOverridden removes the resources declared in the config at path which
+// have the same name as a resource declared in another config
+func dropOverridden[T any, PT interface {
+	*T
+	Resource
+}](resources []T, path string, getName func(*T) string) []T {
+	names := make(map[string]bool)
+	for i := range resources {
+		if PT(&resources[i]).GetContext() != path {
+			names[getName(&resources[i])] = true
+		}
+	}
+
+	return slices.DeleteFunc(resources, func(r T) bool {
+		return PT(&r).GetContext() == path && names[getName(&r)]
+	})
+}
+
 func concatErrors(ci ConfigResources, cycles *[]NodeLink) error {
 	var configErr = ""
 
@@ -178,6 +323,12 @@ func concatErrors(ci ConfigResources, cycles *[]NodeLink) error {
 		}
 	}
 
+	for _, config := range ci.ConfigErrors {
+		if len(config.Errors) > 0 {
+			configErr = fmt.Sprintf("%s%s", configErr, FormatErrors(config.Resource, config.Errors))
+		}
+	}
+
 	if configErr != "" {
 		return &core.ConfigErr{Msg: configErr}
 	}
@@ -185,7 +336,7 @@ func concatErrors(ci ConfigResources, cycles *[]NodeLink) error {
 	return nil
 }
 
-func parseConfig(path string, ci *ConfigResources) ([]Import, error) {
+func parseConfig(path string, namespace string, ci *ConfigResources) ([]Import, error) {
 	dat, err := os.ReadFile(path)
 	if err != nil {
 		return []Import{}, err
@@ -197,29 +348,36 @@ func parseConfig(path string, ci *ConfigResources) ([]Import, error) {
 	}
 
 	// Found config, now try to read it
-	var config Config
-	err = yaml.Unmarshal(dat, &config)
+	config := Config{Path: absPath, Dir: filepath.Dir(absPath)}
+	deprecations, err := decodeConfig(dat, &config)
+	ci.Deprecations = append(ci.Deprecations, deprecations...)
 	if err != nil {
 		return []Import{}, err
 	}
-
-	config.Path = absPath
-	config.Dir = filepath.Dir(absPath)
-	imports := config.loadResources(ci)
+	imports := config.loadResources(ci, namespace)
 
 	return imports, nil
 }
 
-func (c Config) loadResources(ci *ConfigResources) []Import {
+// loadResources appends the resources of the config to ci, task and project
+// names are prefixed with the namespace
+func (c Config) loadResources(ci *ConfigResources, namespace string) []Import {
+	// ${VAR} resolves to the env of the active profile, then the env of this
+	// config, then the env of the configs loaded before it, starting with the
+	// main config
+	envCache, envCacheErrors := ParseEnvCache(c.Env)
+	if len(envCacheErrors) > 0 {
+		ci.ConfigErrors = append(ci.ConfigErrors, ResourceErrors[Config]{Resource: &c, Errors: envCacheErrors})
+	}
+	c.interpolationEnv = MergeEnvs(ci.profileEnv, c.GetEnvList(), ci.Envs)
+	c.interpolationCache = MergeEnvCaches(ci.profileEnvCache, envCache, ci.EnvCache)
+
 	imports, importErrors := c.GetImportList()
 	ci.ImportErrors = append(ci.ImportErrors, importErrors...)
 
 	tasks, taskErrors := c.GetTaskList()
 	ci.TaskErrors = append(ci.TaskErrors, taskErrors...)
 
-	projects, projectErrors := c.GetProjectList()
-	ci.ProjectErrors = append(ci.ProjectErrors, projectErrors...)
-
 	themes, themeErrors := c.ParseThemes()
 	ci.ThemeErrors = append(ci.ThemeErrors, themeErrors...)
 
@@ -231,69 +389,237 @@ func (c Config) loadResources(ci *ConfigResources) []Import {
 
 	envs := c.GetEnvList()
 
+	envFiles, envFileErrors := ParseEnvFiles(c.EnvFiles, c.Dir)
+	if len(envFileErrors) > 0 {
+		ci.ConfigErrors = append(ci.ConfigErrors, ResourceErrors[Config]{Resource: &c, Errors: envFileErrors})
+	}
+
+	if namespace != "" {
+		for i := range tasks {
+			tasks[i].Namespace = namespace
+			tasks[i].Name = qualifyName(namespace, tasks[i].Name)
+		}
+	}
+
 	ci.Imports = append(ci.Imports, imports...)
 	ci.Tasks = append(ci.Tasks, tasks...)
-	ci.Projects = append(ci.Projects, projects...)
+	ci.ProjectTemplates.Kind = yaml.MappingNode
+	if c.ProjectTemplates.Kind == yaml.MappingNode {
+		ci.ProjectTemplates.Content = append(ci.ProjectTemplates.Content, c.ProjectTemplates.Content...)
+	}
+	ci.projectConfigs = append(ci.projectConfigs, projectConfig{config: c, namespace: namespace})
 	ci.Themes = append(ci.Themes, themes...)
 	ci.Specs = append(ci.Specs, specs...)
 	ci.Targets = append(ci.Targets, targets...)
 	ci.Envs = append(ci.Envs, envs...)
+	ci.EnvCache = MergeEnvCaches(ci.EnvCache, envCache)
+	ci.EnvFiles = append(ci.EnvFiles, envFiles...)
 
 	return imports
 }
 
-func dfs(n *Node, m map[string]*Node, cycles *[]NodeLink, ci *ConfigResources) {
-	n.Visiting = true
+// loadProjects appends the projects of the loaded configs to ci, project names
+// are prefixed with the namespace of their config
+func (ci *ConfigResources) loadProjects() {
+	for _, pc := range ci.projectConfigs {
+		pc.config.projectTemplates = ci.ProjectTemplates
 
-	for i := range n.Imports {
-		p, err := core.GetAbsolutePath(filepath.Dir(n.Path), n.Imports[i].Path, "")
+		projects, projectErrors := pc.config.GetProjectList()
+		ci.ProjectErrors = append(ci.ProjectErrors, projectErrors...)
+
+		if pc.namespace != "" {
+			for i := range projects {
+				projects[i].Namespace = pc.namespace
+				projects[i].Name = qualifyName(pc.namespace, projects[i].Name)
+			}
+		}
+
+		ci.Projects = append(ci.Projects, projects...)
+	}
+}
+
+// resolveImportPaths returns the absolute paths of an import, sorted by path.
+// Remote imports are resolved to the config file in the import cache, glob
+// patterns to the matching files and directories to the YAML files in them.
+// Globs and directories skip the importing config itself.
+func resolveImportPaths(configPath string, importPath string) ([]string, error) {
+	if IsRemoteImport(importPath) {
+		r, err := ParseRemoteImport(importPath)
 		if err != nil {
-			importError := ResourceErrors[Import]{Resource: &n.Imports[i], Errors: core.StringsToErrors(err.(*yaml.TypeError).Errors)}
-			ci.ImportErrors = append(ci.ImportErrors, importError)
-			continue
+			return []string{}, err
 		}
 
-		// Skip visited nodes
-		var nc Node
-		v, exists := m[p]
-		if exists {
-			nc = *v
-		} else {
-			nc = Node{Path: p}
-			m[nc.Path] = &nc
+		p, err := r.Resolve()
+		if err != nil {
+			return []string{}, err
 		}
 
-		if nc.Visited {
-			continue
+		return []string{p}, nil
+	}
+
+	p, err := core.GetAbsolutePath(filepath.Dir(configPath), importPath, "")
+	if err != nil {
+		return []string{}, err
+	}
+
+	var paths []string
+	if isGlobPattern(p) {
+		paths, err = globFiles(p)
+		if err != nil {
+			return []string{}, err
+		}
+	} else if info, err := os.Stat(p); err == nil && info.IsDir() {
+		entries, err := os.ReadDir(p)
+		if err != nil {
+			return []string{}, err
 		}
 
-		// Found cyclic dependency
-		if nc.Visiting {
-			c := NodeLink{
-				A: *n,
-				B: nc,
+		for _, entry := range entries {
+			ext := filepath.Ext(entry.Name())
+			if !entry.IsDir() && slices.Contains(CONFIG_EXTENSIONS, ext) {
+				paths = append(paths, filepath.Join(p, entry.Name()))
 			}
+		}
+	} else {
+		return []string{p}, nil
+	}
+
+	paths = slices.DeleteFunc(paths, func(path string) bool { return path == configPath })
+	sort.Strings(paths)
+
+	return paths, nil
+}
+
+func isGlobPattern(path string) bool {
+	return strings.ContainsAny(path, "*?[")
+}
+
+// globFiles returns the files matching the pattern, where ** matches any
+// number of directories. Hidden directories are not searched.
+func globFiles(pattern string) ([]string, error) {
+	// Search from the longest directory without glob characters
+	segments := strings.Split(filepath.ToSlash(pattern), "/")
+	base := 0
+	for base < len(segments)-1 && !isGlobPattern(segments[base]) {
+		base++
+	}
+	root := filepath.FromSlash(strings.Join(segments[:base], "/"))
+	if root == "" {
+		root = "/"
+	}
+	patternSegments := segments[base:]
+
+	var matches []string
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return filepath.SkipAll
+			}
+			return nil
+		}
+
+		if d.IsDir() {
+			if path != root && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return nil
+		}
+
+		if matchGlobSegments(patternSegments, strings.Split(filepath.ToSlash(rel), "/")) {
+			matches = append(matches, path)
+		}
+
+		return nil
+	})
+
+	return matches, err
+}
+
+func matchGlobSegments(pattern []string, path []string) bool {
+	if len(pattern) == 0 {
+		return len(path) == 0
+	}
 
-			*cycles = append(*cycles, c)
-			break
+	if pattern[0] == "**" {
+		// Match zero or more directories
+		for i := 0; i <= len(path); i++ {
+			if matchGlobSegments(pattern[1:], path[i:]) {
+				return true
+			}
 		}
+		return false
+	}
+
+	if len(path) == 0 {
+		return false
+	}
+
+	matched, err := filepath.Match(pattern[0], path[0])
+	if err != nil || !matched {
+		return false
+	}
 
-		// Import Config
-		imports, err := parseConfig(nc.Path, ci)
+	return matchGlobSegments(pattern[1:], path[1:])
+}
+
+func dfs(n *Node, m map[string]*Node, cycles *[]NodeLink, ci *ConfigResources) {
+	n.Visiting = true
+
+out:
+	for i := range n.Imports {
+		paths, err := resolveImportPaths(n.Path, n.Imports[i].Path)
 		if err != nil {
 			importError := ResourceErrors[Import]{Resource: &n.Imports[i], Errors: []error{err}}
+			if typeErr, ok := err.(*yaml.TypeError); ok {
+				importError.Errors = core.StringsToErrors(typeErr.Errors)
+			}
 			ci.ImportErrors = append(ci.ImportErrors, importError)
 			continue
 		}
 
-		nc.Imports = imports
+		for _, p := range paths {
+			// Skip visited nodes
+			var nc Node
+			v, exists := m[p]
+			if exists {
+				nc = *v
+			} else {
+				nc = Node{Path: p, Namespace: joinNamespace(n.Namespace, n.Imports[i].Namespace)}
+				m[nc.Path] = &nc
+			}
 
-		dfs(&nc, m, cycles, ci)
+			if nc.Visited {
+				continue
+			}
 
-		// err = dfs(&nc, m, cycles, ci)
-		// if err != nil {
-		// 	return err
-		// }
+			// Found cyclic dependency
+			if nc.Visiting {
+				c := NodeLink{
+					A: *n,
+					B: nc,
+				}
+
+				*cycles = append(*cycles, c)
+				break out
+			}
+
+			// Import Config
+			imports, err := parseConfig(nc.Path, nc.Namespace, ci)
+			if err != nil {
+				importError := ResourceErrors[Import]{Resource: &n.Imports[i], Errors: []error{err}}
+				ci.ImportErrors = append(ci.ImportErrors, importError)
+				continue
+			}
+
+			nc.Imports = imports
+
+			dfs(&nc, m, cycles, ci)
+		}
 	}
 
 	n.Visiting = false
@@ -0,0 +1,468 @@
+package dao
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/alajmo/mani/core"
+)
+
+const (
+	GetterGit     = "git"
+	GetterArchive = "archive"
+	GetterFile    = "file"
+)
+
+// REMOTE_IMPORT_TIMEOUT limits how long fetching a remote import may take,
+// so an unresponsive host doesn't block every command
+const REMOTE_IMPORT_TIMEOUT = 2 * time.Minute
+
+var archiveSuffixes = []string{".tar.gz", ".tgz", ".zip"}
+
+var remoteImportClient = &http.Client{Timeout: REMOTE_IMPORT_TIMEOUT}
+
+// RemoteImport is an import that is fetched into the local cache, either a git
+// repository (git+https://host/repo.git//path/mani.yaml?ref=v1), an archive
+// (https://host/tasks.tar.gz//path/mani.yaml) or a single file
+// (https://host/tasks.yaml).
+type RemoteImport struct {
+	Source  string
+	Getter  string
+	URL     string
+	Ref     string
+	SubPath string
+}
+
+// IsRemoteImport returns true if the import path is a url
+func IsRemoteImport(importPath string) bool {
+	return strings.HasPrefix(importPath, "git+") || strings.Contains(importPath, "://")
+}
+
+func ParseRemoteImport(source string) (RemoteImport, error) {
+	r := RemoteImport{Source: source, Getter: GetterFile}
+
+	src := source
+	if strings.HasPrefix(src, "git+") {
+		r.Getter = GetterGit
+		src = strings.TrimPrefix(src, "git+")
+	}
+
+	schemeEnd := strings.Index(src, "://")
+	if schemeEnd < 0 {
+		return r, &core.InvalidRemoteImport{Source: source, Reason: "missing url scheme"}
+	}
+
+	// Query, only ref is used by mani, the rest is kept in the url
+	if i := strings.LastIndex(src, "?"); i >= 0 {
+		query, err := url.ParseQuery(src[i+1:])
+		if err != nil {
+			return r, &core.InvalidRemoteImport{Source: source, Reason: err.Error()}
+		}
+		src = src[:i]
+
+		r.Ref = query.Get("ref")
+		query.Del("ref")
+		if len(query) > 0 {
+			src = fmt.Sprintf("%s?%s", src, query.Encode())
+		}
+	}
+
+	// Path inside the repository or archive
+	rest := src[schemeEnd+3:]
+	if strings.HasPrefix(src, "file://") {
+		// file:///abs/path, skip the leading slash of the path
+		rest = strings.TrimPrefix(rest, "/")
+	}
+	if i := strings.Index(rest, "//"); i >= 0 {
+		r.SubPath = strings.TrimPrefix(rest[i+2:], "/")
+		src = src[:len(src)-len(rest)+i]
+	}
+	r.URL = src
+
+	if r.Getter == GetterFile {
+		for _, suffix := range archiveSuffixes {
+			if strings.HasSuffix(strings.ToLower(r.URL), suffix) {
+				r.Getter = GetterArchive
+			}
+		}
+	}
+
+	// The sub path must stay inside the fetched repository or archive
+	if r.SubPath != "" {
+		parts := strings.FieldsFunc(r.SubPath, func(c rune) bool { return c == '/' || c == '\\' })
+		if filepath.IsAbs(r.SubPath) || path.IsAbs(r.SubPath) || slices.Contains(parts, "..") {
+			return r, &core.InvalidRemoteImport{Source: source, Reason: fmt.Sprintf("invalid sub path `%s`", r.SubPath)}
+		}
+	}
+
+	if r.Getter == GetterFile && r.SubPath != "" {
+		return r, &core.InvalidRemoteImport{Source: source, Reason: "sub path is only supported for git repositories and archives"}
+	}
+
+	if r.Getter != GetterGit && r.Ref != "" {
+		return r, &core.InvalidRemoteImport{Source: source, Reason: "ref is only supported for git repositories"}
+	}
+
+	return r, nil
+}
+
+// GetImportCacheDir returns the directory remote imports are cached in
+func GetImportCacheDir() (string, error) {
+	if dir, present := os.LookupEnv("MANI_CACHE_DIR"); present {
+		return filepath.Join(dir, "imports"), nil
+	}
+
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "mani", "imports"), nil
+}
+
+// CacheDir returns the cache directory of the import. Imports of the same
+// repository with different refs are cached separately.
+func (r RemoteImport) CacheDir() (string, error) {
+	dir, err := GetImportCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join([]string{r.Getter, r.URL, r.Ref}, "\n")))
+	return filepath.Join(dir, hex.EncodeToString(sum[:8])), nil
+}
+
+// IsCached returns true if the import has been fetched
+func (r RemoteImport) IsCached() bool {
+	dir, err := r.CacheDir()
+	if err != nil {
+		return false
+	}
+
+	_, err = os.Stat(dir)
+	return err == nil
+}
+
+// Resolve returns the path of the cached config file, the import is fetched
+// if it's not cached
+func (r RemoteImport) Resolve() (string, error) {
+	dir, err := r.CacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	if !r.IsCached() {
+		err := r.Fetch()
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if r.SubPath != "" {
+		return archivePath(dir, r.SubPath)
+	}
+
+	if r.Getter == GetterFile {
+		return filepath.Join(dir, r.fileName()), nil
+	}
+
+	for _, name := range ACCEPTABLE_FILE_NAMES {
+		p := filepath.Join(dir, name)
+		if _, err := os.Stat(p); err == nil {
+			return p, nil
+		}
+	}
+
+	return "", &core.InvalidRemoteImport{Source: r.Source, Reason: fmt.Sprintf("cannot find any configuration file %v", ACCEPTABLE_FILE_NAMES)}
+}
+
+// Fetch downloads the import into the cache, replacing the cached copy only
+// if the download succeeds
+func (r RemoteImport) Fetch() error {
+	dir, err := r.CacheDir()
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(dir), os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	tmpDir, err := os.MkdirTemp(filepath.Dir(dir), filepath.Base(dir)+".tmp-")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = os.RemoveAll(tmpDir)
+	}()
+
+	switch r.Getter {
+	case GetterGit:
+		err = r.fetchGit(tmpDir)
+	case GetterArchive:
+		err = r.fetchArchive(tmpDir)
+	default:
+		err = r.fetchFile(filepath.Join(tmpDir, r.fileName()))
+	}
+	if err != nil {
+		return &core.FailedToFetchImport{Source: r.Source, Err: err}
+	}
+
+	err = os.RemoveAll(dir)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmpDir, dir)
+}
+
+func (r RemoteImport) fileName() string {
+	u, err := url.Parse(r.URL)
+	if err != nil || path.Base(u.Path) == "/" || path.Base(u.Path) == "." {
+		return "mani.yaml"
+	}
+
+	return path.Base(u.Path)
+}
+
+func (r RemoteImport) fetchGit(dir string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), REMOTE_IMPORT_TIMEOUT)
+	defer cancel()
+
+	runGit := func(args ...string) error {
+		cmd := exec.CommandContext(ctx, "git", args...)
+		cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+		output, err := cmd.CombinedOutput()
+		if ctx.Err() != nil {
+			return fmt.Errorf("timed out after %s", REMOTE_IMPORT_TIMEOUT)
+		}
+		if err != nil {
+			return fmt.Errorf("%s", gitErrorMessage(string(output)))
+		}
+		return nil
+	}
+
+	if r.Ref == "" {
+		return runGit("clone", "--depth", "1", r.URL, dir)
+	}
+
+	// Branches and tags can be cloned directly, commits require a full clone
+	err := runGit("clone", "--depth", "1", "--branch", r.Ref, r.URL, dir)
+	if err == nil {
+		return nil
+	}
+
+	_ = os.RemoveAll(dir)
+	err = runGit("clone", "--no-checkout", r.URL, dir)
+	if err != nil {
+		return err
+	}
+
+	return runGit("-C", dir, "checkout", "--quiet", r.Ref)
+}
+
+// gitErrorMessage returns the first error line of git output
+func gitErrorMessage(output string) string {
+	for line := range strings.SplitSeq(output, "\n") {
+		if strings.HasPrefix(line, "fatal: ") || strings.HasPrefix(line, "error: ") {
+			return line
+		}
+	}
+
+	return strings.TrimSpace(output)
+}
+
+// open returns a reader for http(s) and file urls
+func (r RemoteImport) open() (io.ReadCloser, error) {
+	u, err := url.Parse(r.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	switch u.Scheme {
+	case "file":
+		return os.Open(filepath.FromSlash(u.Path))
+	case "http", "https":
+		resp, err := remoteImportClient.Get(r.URL)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			_ = resp.Body.Close()
+			return nil, fmt.Errorf("unexpected status %s", resp.Status)
+		}
+		return resp.Body, nil
+	default:
+		return nil, fmt.Errorf("unsupported url scheme `%s`", u.Scheme)
+	}
+}
+
+func (r RemoteImport) fetchFile(dest string) (err error) {
+	reader, err := r.open()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		closeErr := reader.Close()
+		if err == nil {
+			err = closeErr
+		}
+	}()
+
+	file, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		closeErr := file.Close()
+		if err == nil {
+			err = closeErr
+		}
+	}()
+
+	_, err = io.Copy(file, reader)
+	return err
+}
+
+func (r RemoteImport) fetchArchive(dir string) error {
+	archive := filepath.Join(dir, ".archive")
+	err := r.fetchFile(archive)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = os.Remove(archive)
+	}()
+
+	if strings.HasSuffix(strings.ToLower(r.URL), ".zip") {
+		return extractZip(archive, dir)
+	}
+
+	return extractTarGz(archive, dir)
+}
+
+// archivePath returns the destination of an archive entry, entries outside of
+// the destination directory are rejected
+func archivePath(dir string, name string) (string, error) {
+	p := filepath.Join(dir, filepath.FromSlash(name))
+	if p != dir && !strings.HasPrefix(p, dir+string(os.PathSeparator)) {
+		return "", fmt.Errorf("invalid path `%s` in archive", name)
+	}
+
+	return p, nil
+}
+
+func writeArchiveFile(dest string, reader io.Reader, mode os.FileMode) (err error) {
+	err = os.MkdirAll(filepath.Dir(dest), os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode.Perm()|0600)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		closeErr := file.Close()
+		if err == nil {
+			err = closeErr
+		}
+	}()
+
+	_, err = io.Copy(file, reader)
+	return err
+}
+
+func extractTarGz(archive string, dir string) (err error) {
+	file, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return err
+	}
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		dest, err := archivePath(dir, header.Name)
+		if err != nil {
+			return err
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(dest, os.ModePerm)
+		case tar.TypeReg:
+			err = writeArchiveFile(dest, tr, header.FileInfo().Mode())
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func extractZip(archive string, dir string) error {
+	zr, err := zip.OpenReader(archive)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = zr.Close()
+	}()
+
+	for _, f := range zr.File {
+		dest, err := archivePath(dir, f.Name)
+		if err != nil {
+			return err
+		}
+
+		if f.FileInfo().IsDir() {
+			err = os.MkdirAll(dest, os.ModePerm)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		reader, err := f.Open()
+		if err != nil {
+			return err
+		}
+		err = writeArchiveFile(dest, reader, f.Mode())
+		_ = reader.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,130 @@
+package dao
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestImport_ParseRemoteImport(t *testing.T) {
+	tests := []struct {
+		source      string
+		expected    RemoteImport
+		expectError bool
+	}{
+		{
+			source:   "git+https://example.com/org/tasks.git//shared/tasks.yaml?ref=v2",
+			expected: RemoteImport{Getter: GetterGit, URL: "https://example.com/org/tasks.git", Ref: "v2", SubPath: "shared/tasks.yaml"},
+		},
+		{
+			source:   "git+ssh://git@example.com/org/tasks.git",
+			expected: RemoteImport{Getter: GetterGit, URL: "ssh://git@example.com/org/tasks.git"},
+		},
+		{
+			source:   "file:///opt/tasks.tar.gz//pkg/mani.yaml",
+			expected: RemoteImport{Getter: GetterArchive, URL: "file:///opt/tasks.tar.gz", SubPath: "pkg/mani.yaml"},
+		},
+		{
+			source:   "https://example.com/tasks.yaml?token=abc",
+			expected: RemoteImport{Getter: GetterFile, URL: "https://example.com/tasks.yaml?token=abc"},
+		},
+		{
+			source:      "https://example.com/tasks.yaml?ref=v1",
+			expectError: true,
+		},
+		{
+			source:      "https://example.com/tasks.yaml//sub.yaml",
+			expectError: true,
+		},
+		{
+			source:      "git+https://example.com/org/tasks.git//../../x.yaml",
+			expectError: true,
+		},
+		{
+			source:      "file:///opt/tasks.tar.gz//pkg/..\\..\\x.yaml",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			r, err := ParseRemoteImport(tt.source)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("expected error, got %+v", r)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			tt.expected.Source = tt.source
+			if r != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, r)
+			}
+		})
+	}
+}
+
+func TestImport_ResolveRemoteImport(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MANI_CACHE_DIR", filepath.Join(dir, "cache"))
+
+	// Plain file
+	plain := filepath.Join(dir, "tasks.yaml")
+	if err := os.WriteFile(plain, []byte("tasks: {}\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	// Archive
+	archive := filepath.Join(dir, "tasks.tar.gz")
+	file, err := os.Create(archive)
+	if err != nil {
+		t.Fatalf("failed to create archive: %v", err)
+	}
+	gz := gzip.NewWriter(file)
+	tw := tar.NewWriter(gz)
+	content := []byte("tasks: {}\n")
+	_ = tw.WriteHeader(&tar.Header{Name: "pkg/mani.yaml", Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg})
+	_, _ = tw.Write(content)
+	_ = tw.Close()
+	_ = gz.Close()
+	_ = file.Close()
+
+	for _, source := range []string{
+		"file://" + filepath.ToSlash(plain),
+		"file://" + filepath.ToSlash(archive) + "//pkg/mani.yaml",
+	} {
+		r, err := ParseRemoteImport(source)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		path, err := r.Resolve()
+		if err != nil {
+			t.Fatalf("failed to resolve %s: %v", source, err)
+		}
+
+		dat, err := os.ReadFile(path)
+		if err != nil || string(dat) != string(content) {
+			t.Errorf("expected cached file with content %q, got %q (%v)", content, dat, err)
+		}
+	}
+
+	// Cached copy is kept when the source is gone
+	if err := os.Remove(archive); err != nil {
+		t.Fatal(err)
+	}
+
+	r, _ := ParseRemoteImport("file://" + filepath.ToSlash(archive) + "//pkg/mani.yaml")
+	if err := r.Fetch(); err == nil {
+		t.Errorf("expected fetch of missing archive to fail")
+	}
+	if _, err := r.Resolve(); err != nil {
+		t.Errorf("expected cached copy to be used, got %v", err)
+	}
+}
@@ -0,0 +1,158 @@
+package dao
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeTestFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), os.ModePerm); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+	}
+}
+
+func TestImport_ResolveImportPaths(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"mani.yaml":              "",
+		"other.yaml":             "",
+		"tasks/b.yaml":           "",
+		"tasks/a.yaml":           "",
+		"tasks/readme.md":        "",
+		"teams/x/mani.yaml":      "",
+		"teams/x/y/mani.yaml":    "",
+		"teams/.hidden/mani.yml": "",
+		"lib/one.yml":            "",
+		"lib/nested/two.yaml":    "",
+	})
+	configPath := filepath.Join(dir, "mani.yaml")
+
+	tests := []struct {
+		importPath string
+		expected   []string
+	}{
+		{importPath: "tasks/*.yaml", expected: []string{"tasks/a.yaml", "tasks/b.yaml"}},
+		{importPath: "teams/**/mani.yaml", expected: []string{"teams/x/mani.yaml", "teams/x/y/mani.yaml"}},
+		{importPath: "lib", expected: []string{"lib/one.yml"}},
+		{importPath: "*.yaml", expected: []string{"other.yaml"}},
+		{importPath: "missing/*.yaml", expected: nil},
+		{importPath: "other.yaml", expected: []string{"other.yaml"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.importPath, func(t *testing.T) {
+			paths, err := resolveImportPaths(configPath, tt.importPath)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var got []string
+			for _, p := range paths {
+				rel, _ := filepath.Rel(dir, p)
+				got = append(got, filepath.ToSlash(rel))
+			}
+
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestImport_DuplicateNames(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"mani.yaml":    "import:\n  - tasks/*.yaml\n",
+		"tasks/a.yaml": "tasks:\n  build:\n    cmd: echo a\n",
+		"tasks/b.yaml": "tasks:\n  test:\n    cmd: echo b\n\n  build:\n    cmd: echo b\n",
+	})
+
+	_, err := ReadConfig(filepath.Join(dir, "mani.yaml"), "", "", false)
+	if err == nil {
+		t.Fatalf("expected duplicate task error")
+	}
+
+	msg := err.Error()
+	first := filepath.Join(dir, "tasks", "a.yaml") + ":2"
+	second := filepath.Join(dir, "tasks", "b.yaml") + ":5"
+	if !strings.Contains(msg, "duplicate task `build`") || !strings.Contains(msg, first) || !strings.Contains(msg, second) {
+		t.Errorf("expected error to reference %s and %s, got:\n%s", first, second, msg)
+	}
+}
+
+func TestImport_UserConfigOverridden(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"mani.yaml":   "tasks:\n  build:\n    cmd: echo main\n",
+		"config.yaml": "tasks:\n  build:\n    cmd: echo user\n\n  lint:\n    cmd: echo lint\n",
+	})
+
+	// The main config takes precedence over the user config
+	config, err := ReadConfig(filepath.Join(dir, "mani.yaml"), filepath.Join(dir, "config.yaml"), "", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if names := config.GetTaskNames(); !reflect.DeepEqual(names, []string{"build", "lint"}) {
+		t.Errorf("expected tasks [build lint], got %v", names)
+	}
+	task, _ := config.GetTask("build")
+	if task.Cmd != "echo main" {
+		t.Errorf("expected build from the main config, got `%s`", task.Cmd)
+	}
+}
+
+func TestImport_Namespaces(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"mani.yaml": "import:\n  - path: a.yaml\n    as: team-a\n  - path: b.yaml\n    as: team-b\n",
+		"a.yaml":    "projects:\n  api:\n    path: api\n\ntasks:\n  build: echo a\n\n  deploy:\n    commands:\n      - task: build\n",
+		"b.yaml":    "tasks:\n  build: echo b\n\n  test: echo test\n",
+	})
+
+	config, err := ReadConfig(filepath.Join(dir, "mani.yaml"), "", "", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{"team-a.build", "team-a.deploy", "team-b.build", "team-b.test"}
+	if !reflect.DeepEqual(config.GetTaskNames(), expected) {
+		t.Errorf("expected tasks %v, got %v", expected, config.GetTaskNames())
+	}
+
+	// Unambiguous unqualified names
+	task, err := config.GetTask("test")
+	if err != nil || task.Name != "team-b.test" {
+		t.Errorf("expected task `team-b.test`, got %v, %v", task, err)
+	}
+
+	project, err := config.GetProject("api")
+	if err != nil || project.Name != "team-a.api" {
+		t.Errorf("expected project `team-a.api`, got %v, %v", project, err)
+	}
+
+	// Ambiguous unqualified name
+	_, err = config.GetTask("build")
+	if err == nil || !strings.Contains(err.Error(), "ambiguous") {
+		t.Errorf("expected ambiguous task error, got %v", err)
+	}
+
+	// References inside an import resolve to the same namespace
+	task, err = config.GetTask("team-a.deploy")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if task.Commands[0].Name != "team-a.build" {
+		t.Errorf("expected sub-task `team-a.build`, got `%s`", task.Commands[0].Name)
+	}
+}
@@ -0,0 +1,97 @@
+package dao
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/alajmo/mani/core"
+)
+
+// interpolate replaces ${VAR} in s with the variable from the config env, or
+// the environment mani runs in. Dynamic env values are evaluated when
+// referenced. Variables which are not set are an error if strict, otherwise
+// they're kept as is.
+func (c Config) interpolate(s string, strict bool) (string, error) {
+	var errs []error
+	s = envVariableRegex.ReplaceAllStringFunc(s, func(match string) string {
+		name := envVariableRegex.FindStringSubmatch(match)[1]
+		value, found, err := c.lookupVariable(name)
+		if err != nil {
+			errs = append(errs, err)
+			return match
+		}
+		if !found {
+			if strict {
+				errs = append(errs, &core.EnvNotSet{Name: name})
+			}
+			return match
+		}
+		return value
+	})
+
+	return s, errors.Join(errs...)
+}
+
+func (c Config) lookupVariable(name string) (string, bool, error) {
+	vars, cache := c.interpolationEnv, c.interpolationCache
+	if vars == nil {
+		vars, cache = c.EnvList, c.EnvCache
+	}
+
+	for _, env := range vars {
+		key, _, _ := strings.Cut(env, "=")
+		if key != name {
+			continue
+		}
+
+		envs, err := EvaluateEnv([]string{env}, cache)
+		if err != nil {
+			return "", false, err
+		}
+		_, value, _ := strings.Cut(envs[0], "=")
+		return strings.TrimSuffix(value, "\n"), true, nil
+	}
+
+	value, found := os.LookupEnv(name)
+	return value, found, nil
+}
+
+// interpolateProject replaces ${VAR} in the path, url, branch, clone, remotes
+// and worktrees of the project. Variables in clone which are not set are left
+// for the shell running the clone command.
+func (c Config) interpolateProject(project *Project) []error {
+	var errs []error
+	interpolate := func(field *string, strict bool) {
+		value, err := c.interpolate(*field, strict)
+		if err != nil {
+			errs = append(errs, err)
+			return
+		}
+		*field = value
+	}
+
+	interpolate(&project.Path, true)
+	interpolate(&project.URL, true)
+	interpolate(&project.Branch, true)
+	interpolate(&project.Clone, false)
+
+	for i := range project.RemoteList {
+		interpolate(&project.RemoteList[i].URL, true)
+	}
+
+	for i := range project.WorktreeList {
+		wt := &project.WorktreeList[i]
+		defaultBranch := wt.Branch == filepath.Base(wt.Path)
+
+		interpolate(&wt.Path, true)
+		if defaultBranch {
+			wt.Branch = filepath.Base(wt.Path)
+		} else {
+			interpolate(&wt.Branch, true)
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,179 @@
+package dao
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+
+	"github.com/gookit/color"
+
+	"github.com/alajmo/mani/core"
+)
+
+// Lint is a warning about a config which is valid, but likely contains a
+// mistake, such as a spec no task uses or a project directory that is missing
+type Lint struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line,omitempty"`
+}
+
+func newLint(rule string, message string, re Resource) Lint {
+	return Lint{Rule: rule, Message: message, File: re.GetContext(), Line: max(re.GetContextLine(), 0)}
+}
+
+// FormatLints formats the lints in the same way as config errors
+func FormatLints(lints []Lint) string {
+	var msg = ""
+	var warnPrefix = color.FgYellow.Sprintf("warning")
+	var ptrPrefix = color.FgBlue.Sprintf("-->")
+	for _, lint := range lints {
+		if lint.Line > 0 {
+			msg = fmt.Sprintf("%s%s: %s\n  %s %s:%d\n\n", msg, warnPrefix, lint.Message, ptrPrefix, lint.File, lint.Line)
+		} else {
+			msg = fmt.Sprintf("%s%s: %s\n  %s %s\n\n", msg, warnPrefix, lint.Message, ptrPrefix, lint.File)
+		}
+	}
+
+	return msg
+}
+
+// Lint checks the config for deprecated fields, unused resources, references
+// to tags and projects that don't exist, and shells that are not installed
+func (c Config) Lint() []Lint {
+	lints := slices.Clone(c.Deprecations)
+	lints = append(lints, c.lintUnused()...)
+	lints = append(lints, c.lintTargets()...)
+	lints = append(lints, c.lintProjects()...)
+	lints = append(lints, c.lintShells()...)
+
+	return lints
+}
+
+// lintUnused warns about specs, targets and themes not referenced by any task.
+// Resources named default are used by tasks which don't reference any.
+func (c Config) lintUnused() []Lint {
+	var specs, targets, themes []string
+	for _, task := range c.TaskList {
+		specs = append(specs, task.Spec.Value)
+		targets = append(targets, task.Target.Value)
+		themes = append(themes, task.Theme.Value)
+	}
+
+	var lints []Lint
+	unused := func(kind string, name string, used []string, re Resource) {
+		if name == "default" || re.GetContext() == "" || slices.Contains(used, name) {
+			return
+		}
+		lints = append(lints, newLint("unused-"+kind, fmt.Sprintf("%s `%s` is not used by any task", kind, name), re))
+	}
+
+	for _, spec := range c.SpecList {
+		unused("spec", spec.Name, specs, &spec)
+	}
+	for _, target := range c.TargetList {
+		unused("target", target.Name, targets, &target)
+	}
+	for _, theme := range c.ThemeList {
+		unused("theme", theme.Name, themes, &theme)
+	}
+
+	return lints
+}
+
+// lintTargets warns about targets and inline task targets selecting tags no
+// project has, or tag expressions matching no project
+func (c Config) lintTargets() []Lint {
+	tags := c.GetTags()
+
+	var lints []Lint
+	check := func(name string, target Target, re Resource) {
+		for _, tag := range target.Tags {
+			if !slices.Contains(tags, tag) {
+				lints = append(lints, newLint("unknown-tag", fmt.Sprintf("%s references tag `%s` which no project has", name, tag), re))
+			}
+		}
+
+		if target.TagsExpr != "" {
+			projects, err := c.GetProjectsByTagsExpr(target.TagsExpr)
+			if err == nil && len(projects) == 0 {
+				lints = append(lints, newLint("empty-tags-expr", fmt.Sprintf("tags_expr `%s` of %s matches no project", target.TagsExpr, name), re))
+			}
+		}
+	}
+
+	for _, target := range c.TargetList {
+		if target.GetContext() != "" {
+			check(fmt.Sprintf("target `%s`", target.Name), target, &target)
+		}
+	}
+	for _, task := range c.TaskList {
+		if len(task.Target.Content) > 0 {
+			check(fmt.Sprintf("task `%s`", task.Name), task.TargetData, &task)
+		}
+	}
+
+	return lints
+}
+
+// lintProjects warns about projects sharing a path or url, and project
+// directories that don't exist
+func (c Config) lintProjects() []Lint {
+	var lints []Lint
+	paths := map[string]string{}
+	urls := map[string]string{}
+	for _, project := range c.ProjectList {
+		if name, ok := paths[project.Path]; ok {
+			lints = append(lints, newLint("duplicate-path", fmt.Sprintf("projects `%s` and `%s` share path `%s`", name, project.Name, project.Path), &project))
+		} else {
+			paths[project.Path] = project.Name
+		}
+
+		if name, ok := urls[project.URL]; ok && project.URL != "" {
+			lints = append(lints, newLint("duplicate-url", fmt.Sprintf("projects `%s` and `%s` share url `%s`", name, project.Name, project.URL), &project))
+		} else {
+			urls[project.URL] = project.Name
+		}
+
+		if _, err := os.Stat(project.Path); os.IsNotExist(err) {
+			message := fmt.Sprintf("directory of project `%s` does not exist", project.Name)
+			if project.URL != "" {
+				message += ", run `mani sync` to clone it"
+			}
+			lints = append(lints, newLint("missing-path", message, &project))
+		}
+	}
+
+	return lints
+}
+
+// lintShells warns about shell programs of the config, tasks and commands not
+// found in PATH, each program is reported once
+func (c Config) lintShells() []Lint {
+	var lints []Lint
+	checked := map[string]bool{}
+	check := func(program string, name string, re Resource) {
+		if checked[program] {
+			return
+		}
+		checked[program] = true
+
+		if _, err := exec.LookPath(program); err != nil {
+			lints = append(lints, newLint("shell-not-found", fmt.Sprintf("shell `%s` of %s is not found in PATH", program, name), re))
+		}
+	}
+
+	program, _ := core.FormatShellString(c.Shell, "")
+	check(program, "config", &c)
+
+	for _, task := range c.TaskList {
+		check(task.ShellProgram, fmt.Sprintf("task `%s`", task.Name), &task)
+		for _, cmd := range task.Commands {
+			check(cmd.ShellProgram, fmt.Sprintf("command `%s` of task `%s`", cmd.Name, task.Name), &task)
+		}
+	}
+
+	return lints
+}
@@ -0,0 +1,70 @@
+package dao
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestConfig_Lint(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"mani.yaml": `
+specs:
+  unused:
+    output: table
+  used:
+    parallel: true
+
+targets:
+  frontend:
+    tags: [frontend, nope]
+  expr:
+    tags_expr: frontend && backend
+
+projects:
+  a:
+    path: a
+    tags: [frontend]
+  b:
+    path: a
+    url: https://example.com/b.git
+  c:
+    url: https://example.com/b.git
+
+tasks:
+  build:
+    spec: used
+    target: frontend
+    cmd: make
+  test:
+    shell: mani-missing-shell -c
+    cmd: make test
+`,
+		"a/.keep": "",
+	})
+
+	config, err := ReadConfig(filepath.Join(dir, "mani.yaml"), "", "", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var rules []string
+	for _, lint := range config.Lint() {
+		rules = append(rules, lint.Rule+": "+lint.Message)
+	}
+
+	expected := []string{
+		"unused-spec: spec `unused` is not used by any task",
+		"unused-target: target `expr` is not used by any task",
+		"unknown-tag: target `frontend` references tag `nope` which no project has",
+		"empty-tags-expr: tags_expr `frontend && backend` of target `expr` matches no project",
+		"duplicate-path: projects `a` and `b` share path `" + filepath.Join(dir, "a") + "`",
+		"duplicate-url: projects `b` and `c` share url `https://example.com/b.git`",
+		"missing-path: directory of project `c` does not exist, run `mani sync` to clone it",
+		"shell-not-found: shell `mani-missing-shell` of task `test` is not found in PATH",
+	}
+	if !reflect.DeepEqual(rules, expected) {
+		t.Errorf("expected:\n%v\ngot:\n%v", expected, rules)
+	}
+}
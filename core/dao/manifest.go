@@ -0,0 +1,595 @@
+package dao
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/alajmo/mani/core"
+)
+
+var MANIFEST_FORMATS = []string{"repo", "vcstool", "mr", "gita", "meta"}
+
+var commitHashRe = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// GetManifestFormat returns the manifest format based on the file name
+func GetManifestFormat(path string) (string, error) {
+	base := filepath.Base(path)
+	switch {
+	case filepath.Ext(base) == ".xml":
+		return "repo", nil
+	case filepath.Ext(base) == ".repos":
+		return "vcstool", nil
+	case base == ".mrconfig":
+		return "mr", nil
+	case filepath.Ext(base) == ".csv":
+		return "gita", nil
+	case base == ".meta":
+		return "meta", nil
+	default:
+		return "", &core.ManifestFormatUnknown{Path: path}
+	}
+}
+
+// ParseManifest reads a manifest file of another multi-repo tool and returns
+// the projects declared in it. Paths are relative to configDir.
+func ParseManifest(format string, path string, configDir string) ([]Project, error) {
+	dat, err := os.ReadFile(path)
+	if err != nil {
+		return []Project{}, err
+	}
+
+	// Project paths are relative to the manifest directory, except for repo
+	// manifests checked out by repo, where they're relative to the directory of .repo
+	manifestDir := filepath.Dir(path)
+
+	var projects []Project
+	switch format {
+	case "repo":
+		projects, err = parseRepoManifest(dat, manifestDir)
+		manifestDir = repoClientDir(manifestDir)
+	case "vcstool":
+		projects, err = parseVcstoolManifest(dat)
+	case "mr":
+		projects, err = parseMrManifest(dat)
+	case "gita":
+		projects, err = parseGitaManifest(dat, manifestDir)
+	case "meta":
+		projects, err = parseMetaManifest(dat)
+	default:
+		return []Project{}, &core.ManifestFormatInvalid{Format: format, Formats: MANIFEST_FORMATS}
+	}
+
+	if err != nil {
+		return []Project{}, &core.FailedToParseManifest{Path: path, Err: err}
+	}
+
+	for i := range projects {
+		relPath, err := manifestRelPath(projects[i].Path, manifestDir, configDir)
+		if err != nil {
+			return []Project{}, &core.FailedToParseManifest{Path: path, Err: err}
+		}
+
+		projects[i].Path = filepath.ToSlash(relPath)
+		if projects[i].Name == "" {
+			projects[i].Name = filepath.Base(projects[i].Path)
+		}
+	}
+	RenameDuplicates(projects)
+
+	return projects, nil
+}
+
+// ImportProjects adds the projects to the main config file. Projects with a
+// path that is already declared are skipped and returned separately.
+func (c Config) ImportProjects(projects []Project) ([]Project, []Project, error) {
+	var added []Project
+	var skipped []Project
+	for _, p := range projects {
+		if c.getProjectByPath(filepath.Join(c.Dir, p.Path)) != nil {
+			skipped = append(skipped, p)
+			continue
+		}
+		added = append(added, p)
+	}
+
+	if len(added) == 0 {
+		return added, skipped, nil
+	}
+
+	err := c.addProjects(added)
+	return added, skipped, err
+}
+
+// Google repo XML manifest
+
+type repoManifest struct {
+	Remotes        []repoRemote  `xml:"remote"`
+	Default        repoDefault   `xml:"default"`
+	Projects       []repoProject `xml:"project"`
+	RemoveProjects []repoProject `xml:"remove-project"`
+	ExtendProjects []repoProject `xml:"extend-project"`
+	Includes       []struct {
+		Name string `xml:"name,attr"`
+	} `xml:"include"`
+}
+
+type repoRemote struct {
+	Name     string `xml:"name,attr"`
+	Fetch    string `xml:"fetch,attr"`
+	Revision string `xml:"revision,attr"`
+}
+
+type repoDefault struct {
+	Remote   string `xml:"remote,attr"`
+	Revision string `xml:"revision,attr"`
+}
+
+type repoProject struct {
+	Name     string `xml:"name,attr"`
+	Path     string `xml:"path,attr"`
+	Remote   string `xml:"remote,attr"`
+	Revision string `xml:"revision,attr"`
+	Groups   string `xml:"groups,attr"`
+}
+
+// readRepoManifest reads a repo manifest and the manifests it includes, which
+// are resolved relative to the manifest directory
+func readRepoManifest(dat []byte, dir string, manifest *repoManifest) error {
+	var m repoManifest
+	err := xml.Unmarshal(dat, &m)
+	if err != nil {
+		return err
+	}
+
+	manifest.Remotes = append(manifest.Remotes, m.Remotes...)
+	if m.Default.Remote != "" {
+		manifest.Default.Remote = m.Default.Remote
+	}
+	if m.Default.Revision != "" {
+		manifest.Default.Revision = m.Default.Revision
+	}
+	manifest.Projects = append(manifest.Projects, m.Projects...)
+	manifest.RemoveProjects = append(manifest.RemoveProjects, m.RemoveProjects...)
+	manifest.ExtendProjects = append(manifest.ExtendProjects, m.ExtendProjects...)
+
+	for _, include := range m.Includes {
+		dat, err := os.ReadFile(filepath.Join(dir, include.Name))
+		if err != nil {
+			return err
+		}
+
+		err = readRepoManifest(dat, dir, manifest)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func parseRepoManifest(dat []byte, dir string) ([]Project, error) {
+	var manifest repoManifest
+	err := readRepoManifest(dat, dir, &manifest)
+	if err != nil {
+		return []Project{}, err
+	}
+
+	remotes := make(map[string]repoRemote)
+	for _, remote := range manifest.Remotes {
+		remotes[remote.Name] = remote
+	}
+
+	removed := make(map[string]bool)
+	for _, p := range manifest.RemoveProjects {
+		removed[p.Name] = true
+	}
+
+	var projects []Project
+	for _, rp := range manifest.Projects {
+		if removed[rp.Name] {
+			continue
+		}
+
+		for _, ep := range manifest.ExtendProjects {
+			if ep.Name != rp.Name {
+				continue
+			}
+			if ep.Path != "" {
+				rp.Path = ep.Path
+			}
+			if ep.Remote != "" {
+				rp.Remote = ep.Remote
+			}
+			if ep.Revision != "" {
+				rp.Revision = ep.Revision
+			}
+			if ep.Groups != "" {
+				rp.Groups = strings.Join([]string{rp.Groups, ep.Groups}, ",")
+			}
+		}
+
+		remoteName := rp.Remote
+		if remoteName == "" {
+			remoteName = manifest.Default.Remote
+		}
+		remote := remotes[remoteName]
+
+		revision := rp.Revision
+		if revision == "" {
+			revision = remote.Revision
+		}
+		if revision == "" {
+			revision = manifest.Default.Revision
+		}
+		revision = strings.TrimPrefix(revision, "refs/heads/")
+		if commitHashRe.MatchString(revision) || strings.HasPrefix(revision, "refs/") {
+			revision = ""
+		}
+
+		path := rp.Path
+		if path == "" {
+			path = rp.Name
+		}
+
+		// Relative fetch URLs are resolved against the manifest URL, which is not
+		// known here
+		url := ""
+		if remote.Fetch != "" && !strings.HasPrefix(remote.Fetch, ".") {
+			url = strings.TrimSuffix(remote.Fetch, "/") + "/" + rp.Name
+		}
+
+		projects = append(projects, Project{
+			Path:   path,
+			URL:    url,
+			Branch: revision,
+			Tags:   splitGroups(rp.Groups),
+		})
+	}
+
+	return projects, nil
+}
+
+func splitGroups(groups string) []string {
+	var tags []string
+	seen := make(map[string]bool)
+	for _, group := range strings.FieldsFunc(groups, func(r rune) bool { return r == ',' || r == ' ' }) {
+		if !seen[group] {
+			seen[group] = true
+			tags = append(tags, group)
+		}
+	}
+
+	return tags
+}
+
+// vcstool .repos manifest
+
+type vcstoolManifest struct {
+	Repositories yaml.Node `yaml:"repositories"`
+}
+
+type vcstoolRepository struct {
+	Type    string `yaml:"type"`
+	URL     string `yaml:"url"`
+	Version string `yaml:"version"`
+}
+
+func parseVcstoolManifest(dat []byte) ([]Project, error) {
+	var manifest vcstoolManifest
+	err := yaml.Unmarshal(dat, &manifest)
+	if err != nil {
+		return []Project{}, err
+	}
+
+	var projects []Project
+	repos := manifest.Repositories
+	for i := 0; i+1 < len(repos.Content); i += 2 {
+		var repo vcstoolRepository
+		err := repos.Content[i+1].Decode(&repo)
+		if err != nil {
+			return []Project{}, err
+		}
+
+		if repo.Type != "" && repo.Type != "git" {
+			continue
+		}
+
+		version := repo.Version
+		if commitHashRe.MatchString(version) {
+			version = ""
+		}
+
+		projects = append(projects, Project{
+			Path:   repos.Content[i].Value,
+			URL:    repo.URL,
+			Branch: version,
+		})
+	}
+
+	return projects, nil
+}
+
+// myrepos .mrconfig manifest
+
+func parseMrManifest(dat []byte) ([]Project, error) {
+	var projects []Project
+	var section string
+	var checkout string
+
+	addProject := func() error {
+		if section == "" || section == "DEFAULT" || checkout == "" {
+			return nil
+		}
+
+		url, branch := parseGitClone(checkout)
+		if url == "" {
+			return nil
+		}
+
+		projects = append(projects, Project{Path: section, URL: url, Branch: branch})
+		return nil
+	}
+
+	var key string
+	scanner := bufio.NewScanner(bytes.NewReader(dat))
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "" || strings.HasPrefix(trimmed, "#"):
+			continue
+		case strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]"):
+			err := addProject()
+			if err != nil {
+				return []Project{}, err
+			}
+			section = strings.TrimSpace(trimmed[1 : len(trimmed)-1])
+			checkout = ""
+			key = ""
+		case line[0] == ' ' || line[0] == '\t':
+			// Continuation of the previous value
+			if key == "checkout" {
+				checkout += "\n" + trimmed
+			}
+		default:
+			k, v, found := strings.Cut(trimmed, "=")
+			if !found {
+				continue
+			}
+			key = strings.TrimSpace(k)
+			if key == "checkout" {
+				checkout = strings.TrimSpace(v)
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return []Project{}, err
+	}
+
+	err := addProject()
+	if err != nil {
+		return []Project{}, err
+	}
+
+	return projects, nil
+}
+
+// Options of `git clone` that take a separate value
+var gitCloneValueOptions = []string{
+	"-o", "--origin", "-c", "--config", "-u", "--upload-pack", "-j", "--jobs",
+	"--depth", "--reference", "--reference-if-able", "--separate-git-dir",
+	"--shallow-since", "--shallow-exclude", "--filter", "--template",
+}
+
+// parseGitClone returns the url and branch of the first `git clone` command
+func parseGitClone(command string) (string, string) {
+	for _, line := range strings.Split(command, "\n") {
+		args := splitShellWords(line)
+
+		for i := 0; i+1 < len(args); i++ {
+			if args[i] != "git" || args[i+1] != "clone" {
+				continue
+			}
+
+			var url, branch string
+			rest := args[i+2:]
+			for j := 0; j < len(rest); j++ {
+				arg := rest[j]
+				switch {
+				case arg == "-b" || arg == "--branch":
+					if j+1 < len(rest) {
+						branch = rest[j+1]
+						j++
+					}
+				case strings.HasPrefix(arg, "--branch="):
+					branch = strings.TrimPrefix(arg, "--branch=")
+				case slices.Contains(gitCloneValueOptions, arg):
+					// Skip option value
+					j++
+				case strings.HasPrefix(arg, "-"):
+					continue
+				case url == "":
+					url = arg
+				}
+			}
+
+			return url, branch
+		}
+	}
+
+	return "", ""
+}
+
+// splitShellWords splits a shell command into words, handling single and
+// double quotes
+func splitShellWords(command string) []string {
+	var words []string
+	var word strings.Builder
+	var quote rune
+	inWord := false
+
+	for _, r := range command {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			word.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t' || r == ';' || r == '&' || r == '|':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if inWord {
+		words = append(words, word.String())
+	}
+
+	return words
+}
+
+// gita repos.csv manifest
+
+func parseGitaManifest(dat []byte, manifestDir string) ([]Project, error) {
+	reader := csv.NewReader(bytes.NewReader(dat))
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return []Project{}, err
+	}
+
+	groups, err := readGitaGroups(filepath.Join(manifestDir, "groups.csv"))
+	if err != nil {
+		return []Project{}, err
+	}
+
+	var projects []Project
+	for _, record := range records {
+		repoPath := strings.TrimSpace(record[0])
+		if repoPath == "" {
+			continue
+		}
+
+		name := filepath.Base(repoPath)
+		if len(record) > 1 && strings.TrimSpace(record[1]) != "" {
+			name = strings.TrimSpace(record[1])
+		}
+
+		// gita does not store the url, use the remote of the repository if it exists
+		url := ""
+		absPath, err := core.ResolveTildePath(repoPath)
+		if err == nil {
+			url, _ = core.GetRemoteURL(absPath)
+		}
+
+		projects = append(projects, Project{Name: name, Path: repoPath, URL: url, Tags: groups[name]})
+	}
+
+	return projects, nil
+}
+
+// readGitaGroups returns the groups of each repository from a gita groups.csv
+// file, where each line is in the form `group:repo1 repo2` or `group:path:repo1 repo2`
+func readGitaGroups(path string) (map[string][]string, error) {
+	groups := make(map[string][]string)
+
+	dat, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return groups, nil
+	}
+	if err != nil {
+		return groups, err
+	}
+
+	for _, line := range strings.Split(string(dat), "\n") {
+		fields := strings.Split(line, ":")
+		if len(fields) < 2 {
+			continue
+		}
+
+		group := strings.TrimSpace(fields[0])
+		for _, repo := range strings.Fields(fields[len(fields)-1]) {
+			groups[repo] = append(groups[repo], group)
+		}
+	}
+
+	return groups, nil
+}
+
+// meta .meta manifest
+
+type metaManifest struct {
+	Projects map[string]string `json:"projects"`
+}
+
+func parseMetaManifest(dat []byte) ([]Project, error) {
+	var manifest metaManifest
+	err := json.Unmarshal(dat, &manifest)
+	if err != nil {
+		return []Project{}, err
+	}
+
+	var projects []Project
+	for path, url := range manifest.Projects {
+		projects = append(projects, Project{Path: path, URL: url})
+	}
+
+	// Map order is random
+	slices.SortFunc(projects, func(a, b Project) int { return strings.Compare(a.Path, b.Path) })
+
+	return projects, nil
+}
+
+// repoClientDir returns the directory repo checked out the projects in, for a
+// manifest in a .repo directory, otherwise the manifest directory
+func repoClientDir(manifestDir string) string {
+	for dir := manifestDir; dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+		if filepath.Base(dir) == ".repo" {
+			return filepath.Dir(dir)
+		}
+	}
+
+	return manifestDir
+}
+
+// manifestRelPath returns the path of a repository declared in a manifest
+// relative to the mani config directory. Relative paths are resolved from the
+// manifest directory.
+func manifestRelPath(path string, manifestDir string, configDir string) (string, error) {
+	path, err := core.ResolveTildePath(path)
+	if err != nil {
+		return "", err
+	}
+
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(manifestDir, path)
+	}
+
+	relPath, err := filepath.Rel(configDir, path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve path `%s`: %w", path, err)
+	}
+
+	return relPath, nil
+}
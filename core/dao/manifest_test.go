@@ -0,0 +1,171 @@
+package dao
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestManifest_ParseManifest(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   string
+		file     string
+		content  string
+		expected []Project
+	}{
+		{
+			name:   "repo manifest",
+			format: "repo",
+			file:   "default.xml",
+			content: `<manifest>
+  <remote name="origin" fetch="https://example.com/" />
+  <default remote="origin" revision="refs/heads/main" />
+  <project name="platform/build" path="build" groups="core,tools" />
+  <project name="platform/docs" revision="0123456789abcdef0123456789abcdef01234567" />
+  <project name="platform/old" />
+  <remove-project name="platform/old" />
+</manifest>`,
+			expected: []Project{
+				{Name: "build", Path: "build", URL: "https://example.com/platform/build", Branch: "main", Tags: []string{"core", "tools"}},
+				{Name: "docs", Path: "platform/docs", URL: "https://example.com/platform/docs"},
+			},
+		},
+		{
+			name:   "vcstool manifest",
+			format: "vcstool",
+			file:   "ws.repos",
+			content: `repositories:
+  src/foo:
+    type: git
+    url: https://example.com/foo.git
+    version: main
+  src/bar:
+    type: hg
+    url: https://example.com/bar
+`,
+			expected: []Project{
+				{Name: "foo", Path: "src/foo", URL: "https://example.com/foo.git", Branch: "main"},
+			},
+		},
+		{
+			name:   "mr manifest",
+			format: "mr",
+			file:   ".mrconfig",
+			content: `[DEFAULT]
+jobs = 4
+
+[src/foo]
+checkout = git clone --depth 1 -b dev 'https://example.com/foo.git' 'foo'
+
+[src/bar]
+checkout =
+  git clone "https://example.com/bar.git" bar
+`,
+			expected: []Project{
+				{Name: "foo", Path: "src/foo", URL: "https://example.com/foo.git", Branch: "dev"},
+				{Name: "bar", Path: "src/bar", URL: "https://example.com/bar.git"},
+			},
+		},
+		{
+			name:    "meta manifest",
+			format:  "meta",
+			file:    ".meta",
+			content: `{"projects": {"b": "git@example.com:b.git", "a": "git@example.com:a.git"}}`,
+			expected: []Project{
+				{Name: "a", Path: "a", URL: "git@example.com:a.git"},
+				{Name: "b", Path: "b", URL: "git@example.com:b.git"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("failed to write manifest: %v", err)
+			}
+
+			format, err := GetManifestFormat(path)
+			if err != nil || format != tt.format {
+				t.Errorf("expected format %q, got %q (%v)", tt.format, format, err)
+			}
+
+			projects, err := ParseManifest(tt.format, path, dir)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(projects, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, projects)
+			}
+		})
+	}
+}
+
+func TestManifest_RelativePaths(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   string
+		file     string
+		content  string
+		expected []string
+	}{
+		{
+			name:     "repo manifest",
+			format:   "repo",
+			file:     "ws/.repo/manifests/default.xml",
+			content:  `<manifest><project name="platform/build" path="build" /></manifest>`,
+			expected: []string{"ws/build"},
+		},
+		{
+			name:     "vcstool manifest",
+			format:   "vcstool",
+			file:     "ws/ws.repos",
+			content:  "repositories:\n  src/foo:\n    url: https://example.com/foo.git\n",
+			expected: []string{"ws/src/foo"},
+		},
+		{
+			name:     "mr manifest",
+			format:   "mr",
+			file:     "ws/.mrconfig",
+			content:  "[src/foo]\ncheckout = git clone https://example.com/foo.git foo\n",
+			expected: []string{"ws/src/foo"},
+		},
+		{
+			name:     "meta manifest",
+			format:   "meta",
+			file:     "ws/.meta",
+			content:  `{"projects": {"a": "git@example.com:a.git"}}`,
+			expected: []string{"ws/a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, tt.file)
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("failed to write manifest: %v", err)
+			}
+
+			projects, err := ParseManifest(tt.format, path, dir)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var paths []string
+			for _, p := range projects {
+				paths = append(paths, p.Path)
+			}
+			if !reflect.DeepEqual(paths, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, paths)
+			}
+		})
+	}
+}
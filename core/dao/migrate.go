@@ -0,0 +1,162 @@
+package dao
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/alajmo/mani/core"
+)
+
+// CONFIG_VERSION is the version of the config schema. Configs without `version`
+// are read as the current version, configs with a newer version are rejected.
+var CONFIG_VERSION = 1
+
+// fieldRename is a field renamed in an earlier release, the old name is still
+// read but warned about
+type fieldRename struct {
+	old string
+	new string
+}
+
+var (
+	configRenames = []fieldRename{{"commands", "tasks"}}
+	taskRenames   = []fieldRename{{"args", "env"}}
+	specRenames   = []fieldRename{{"omit_empty", "omit_empty_rows"}}
+)
+
+// decodeConfig reads the YAML, TOML or JSON config in dat. Fields renamed in earlier releases are
+// read with their current name and returned as deprecations.
+func decodeConfig(dat []byte, config *Config) ([]Lint, error) {
+	doc, err := parseConfigFile(config.Path, dat)
+	if err != nil {
+		return nil, err
+	}
+
+	// Empty file
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+
+	deprecations := migrateConfig(doc.Content[0], config.Path)
+
+	err = doc.Decode(config)
+	if err != nil {
+		return deprecations, err
+	}
+
+	if config.Version > CONFIG_VERSION {
+		return deprecations, &core.ConfigVersionUnsupported{Version: config.Version, Supported: CONFIG_VERSION}
+	}
+
+	return deprecations, nil
+}
+
+// Migrate rewrites the renamed fields of the file to their current name and
+// sets `version` to the current config version. Returns the renamed fields.
+func (f *YAMLFile) Migrate() []Lint {
+	if len(f.Doc.Content) == 0 || f.Doc.Content[0].Kind != yaml.MappingNode || len(f.Doc.Content[0].Content) == 0 {
+		return nil
+	}
+
+	root := f.Root()
+	first := root.Content[0]
+	lints := migrateConfig(root, f.Path)
+
+	current := strconv.Itoa(CONFIG_VERSION)
+	version := MappingValue(root, "version")
+	if version == nil {
+		node := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: current}
+		root.Content = append([]*yaml.Node{ScalarNode("version"), node}, root.Content...)
+	} else if v, err := strconv.Atoi(version.Value); err != nil || v < CONFIG_VERSION {
+		version.Value = current
+		version.Tag = "!!int"
+		version.Style = 0
+	}
+	f.keepHeadComment(first)
+
+	return lints
+}
+
+type migration struct {
+	path  string
+	lints []Lint
+}
+
+// migrateConfig renames the deprecated fields of a config mapping in place and
+// returns a deprecation for each
+func migrateConfig(root *yaml.Node, path string) []Lint {
+	m := migration{path: path}
+	m.rename(root, configRenames)
+	formatEntries(MappingValue(root, "tasks"), m.task)
+	formatEntries(MappingValue(root, "specs"), m.spec)
+	formatEntries(MappingValue(root, "projects"), m.project)
+	formatEntries(MappingValue(root, "project_templates"), m.project)
+	formatEntries(MappingValue(root, "profiles"), m.profile)
+
+	slices.SortStableFunc(m.lints, func(a, b Lint) int { return a.Line - b.Line })
+
+	return m.lints
+}
+
+func (m *migration) task(node *yaml.Node) {
+	m.rename(node, taskRenames)
+	if commands := MappingValue(node, "commands"); commands != nil && commands.Kind == yaml.SequenceNode {
+		for _, command := range commands.Content {
+			m.rename(command, taskRenames)
+		}
+	}
+	m.spec(MappingValue(node, "spec"))
+}
+
+// project migrates the task overrides of a project
+func (m *migration) project(node *yaml.Node) {
+	formatEntries(MappingValue(node, "tasks"), m.task)
+}
+
+func (m *migration) profile(node *yaml.Node) {
+	formatEntries(MappingValue(node, "specs"), m.spec)
+	formatEntries(MappingValue(node, "projects"), m.project)
+}
+
+func (m *migration) spec(node *yaml.Node) {
+	m.rename(node, specRenames)
+
+	// Output text was renamed to stream
+	if output := MappingValue(node, "output"); output != nil && output.Value == "text" {
+		output.Value = "stream"
+		m.warn(output, "deprecated-value", "`output: text` is deprecated, use `output: stream`")
+	}
+}
+
+// rename renames the deprecated keys of a mapping node. If both the old and the
+// new key are set, the old key is removed since it's ignored.
+func (m *migration) rename(node *yaml.Node, renames []fieldRename) {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return
+	}
+
+	for _, r := range renames {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i]
+			if key.Value != r.old {
+				continue
+			}
+
+			if MappingValue(node, r.new) != nil {
+				m.warn(key, "deprecated-field", fmt.Sprintf("`%s` is deprecated and ignored since `%s` is set", r.old, r.new))
+				node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			} else {
+				m.warn(key, "deprecated-field", fmt.Sprintf("`%s` is deprecated, use `%s`", r.old, r.new))
+				key.Value = r.new
+			}
+			break
+		}
+	}
+}
+
+func (m *migration) warn(node *yaml.Node, rule string, message string) {
+	m.lints = append(m.lints, Lint{Rule: rule, Message: message, File: m.path, Line: node.Line})
+}
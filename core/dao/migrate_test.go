@@ -0,0 +1,162 @@
+package dao
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const deprecatedConfig = `# Main config
+specs:
+  quiet:
+    output: text
+    omit_empty: true
+
+commands:
+  hello:
+    spec: quiet
+    args:
+      NAME: world
+    cmd: echo hello $NAME
+`
+
+func TestConfig_Deprecations(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"mani.yaml": deprecatedConfig})
+
+	config, err := ReadConfig(filepath.Join(dir, "mani.yaml"), "", "", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Renamed fields are read with their current name
+	task, err := config.GetTask("hello")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if task.SpecData.Output != "stream" || !task.SpecData.OmitEmptyRows {
+		t.Fatalf("expected spec with output stream and omit_empty_rows, got %+v", task.SpecData)
+	}
+	if MappingValue(&task.Env, "NAME") == nil {
+		t.Fatalf("expected env NAME to be set")
+	}
+
+	var rules []string
+	for _, lint := range config.Deprecations {
+		rules = append(rules, lint.Rule+":"+strings.Fields(lint.Message)[0])
+	}
+	expected := []string{
+		"deprecated-value:`output:",
+		"deprecated-field:`omit_empty`",
+		"deprecated-field:`commands`",
+		"deprecated-field:`args`",
+	}
+	if strings.Join(rules, " ") != strings.Join(expected, " ") {
+		t.Fatalf("expected deprecations %v, got %v", expected, rules)
+	}
+}
+
+func TestConfig_NestedDeprecations(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"mani.yaml": `
+specs:
+  quiet:
+    output: table
+
+projects:
+  api:
+    tasks:
+      hello:
+        args:
+          NAME: api
+
+tasks:
+  hello:
+    cmd: echo hello $NAME
+
+profiles:
+  ci:
+    specs:
+      quiet:
+        output: text
+`})
+
+	config, err := ReadConfig(filepath.Join(dir, "mani.yaml"), "", "ci", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Profile specs and project task overrides are migrated like the top level
+	spec, _ := config.GetSpec("quiet")
+	if spec.Output != "stream" {
+		t.Errorf("expected profile spec output stream, got %s", spec.Output)
+	}
+
+	var lines []int
+	for _, lint := range config.Deprecations {
+		lines = append(lines, lint.Line)
+	}
+	if !reflect.DeepEqual(lines, []int{10, 21}) {
+		t.Errorf("expected deprecations on lines 10 and 21, got %v", config.Deprecations)
+	}
+}
+
+func TestConfig_VersionUnsupported(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"mani.yaml": "version: 99\n"})
+
+	_, err := ReadConfig(filepath.Join(dir, "mani.yaml"), "", "", false)
+	if err == nil || !strings.Contains(err.Error(), "config version 99 is not supported") {
+		t.Fatalf("expected unsupported version error, got %v", err)
+	}
+}
+
+func TestYAMLFile_Migrate(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"mani.yaml": deprecatedConfig})
+
+	f, err := ReadYAMLFile(filepath.Join(dir, "mani.yaml"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	lints := f.Migrate()
+	if len(lints) != 4 {
+		t.Fatalf("expected 4 renamed fields, got %v", lints)
+	}
+
+	out, err := f.Marshal()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := `# Main config
+
+version: 1
+specs:
+  quiet:
+    output: stream
+    omit_empty_rows: true
+
+tasks:
+  hello:
+    spec: quiet
+    env:
+      NAME: world
+    cmd: echo hello $NAME
+`
+	if string(out) != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, out)
+	}
+
+	diff := FormatDiff("mani.yaml", []byte(deprecatedConfig), out)
+	for _, line := range []string{"-commands:", "+tasks:", "+version: 1", " # Main config"} {
+		if !strings.Contains(diff, line+"\n") {
+			t.Fatalf("expected diff to contain %q, got:\n%s", line, diff)
+		}
+	}
+	if FormatDiff("mani.yaml", out, out) != "" {
+		t.Fatalf("expected no diff for equal files")
+	}
+}
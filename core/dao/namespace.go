@@ -0,0 +1,93 @@
+package dao
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/alajmo/mani/core"
+)
+
+var namespaceRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// joinNamespace returns the namespace of a nested import, a config imported
+// as `b` from a config imported as `a` has the namespace `a.b`
+func joinNamespace(parent string, namespace string) string {
+	if parent == "" {
+		return namespace
+	}
+	if namespace == "" {
+		return parent
+	}
+
+	return parent + "." + namespace
+}
+
+// qualifyName prefixes the name with the namespace
+func qualifyName(namespace string, name string) string {
+	if namespace == "" {
+		return name
+	}
+
+	return namespace + "." + name
+}
+
+// unqualifyName returns the name without the namespace prefix
+func unqualifyName(namespace string, name string) string {
+	if namespace == "" {
+		return name
+	}
+
+	return strings.TrimPrefix(name, namespace+".")
+}
+
+// findByName returns the index of the resource matching name, or -1 if there's
+// no match. getName returns the qualified name and namespace of the resource at
+// index i. Names are matched in the following order:
+//
+//  1. name qualified with the scope, used for references inside an import
+//  2. the qualified name
+//  3. the unqualified name, if exactly one resource matches
+func findByName(kind string, name string, scope string, count int, getName func(i int) (string, string)) (int, error) {
+	if scope != "" {
+		scoped := qualifyName(scope, name)
+		for i := range count {
+			if qualified, _ := getName(i); qualified == scoped {
+				return i, nil
+			}
+		}
+	}
+
+	for i := range count {
+		if qualified, _ := getName(i); qualified == name {
+			return i, nil
+		}
+	}
+
+	index := -1
+	var matches []string
+	for i := range count {
+		qualified, namespace := getName(i)
+		if namespace != "" && unqualifyName(namespace, qualified) == name {
+			index = i
+			matches = append(matches, qualified)
+		}
+	}
+
+	if len(matches) > 1 {
+		return -1, &core.AmbiguousName{Kind: kind, Name: name, Matches: matches}
+	}
+
+	return index, nil
+}
+
+func (c Config) findTask(name string, scope string) (int, error) {
+	return findByName("task", name, scope, len(c.TaskList), func(i int) (string, string) {
+		return c.TaskList[i].Name, c.TaskList[i].Namespace
+	})
+}
+
+func (c Config) findProject(name string) (int, error) {
+	return findByName("project", name, "", len(c.ProjectList), func(i int) (string, string) {
+		return c.ProjectList[i].Name, c.ProjectList[i].Namespace
+	})
+}
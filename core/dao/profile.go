@@ -0,0 +1,259 @@
+package dao
+
+import (
+	"errors"
+	"os"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/alajmo/mani/core"
+)
+
+// Profile overlays env, specs, targets and project fields of the config.
+// Env is merged with the config env, spec, target and project fields set in
+// the profile replace the existing values.
+type Profile struct {
+	Name     string
+	Env      yaml.Node `yaml:"env"`
+	Specs    yaml.Node `yaml:"specs"`
+	Targets  yaml.Node `yaml:"targets"`
+	Projects yaml.Node `yaml:"projects"`
+
+	context     string
+	contextLine int
+}
+
+func (p *Profile) GetContext() string {
+	return p.context
+}
+
+func (p *Profile) GetContextLine() int {
+	return p.contextLine
+}
+
+// getProfileName returns the profile set by flag, or the MANI_PROFILE env
+// variable if the flag is not set
+func getProfileName(profile string) string {
+	if profile != "" {
+		return profile
+	}
+
+	return os.Getenv("MANI_PROFILE")
+}
+
+func (c *Config) GetProfileList() ([]Profile, []ResourceErrors[Profile]) {
+	var profiles []Profile
+	count := len(c.Profiles.Content)
+
+	profileErrors := []ResourceErrors[Profile]{}
+	foundErrors := false
+	for i := 0; i < count; i += 2 {
+		profile := &Profile{
+			Name:        c.Profiles.Content[i].Value,
+			context:     c.Path,
+			contextLine: c.Profiles.Content[i].Line,
+		}
+
+		err := c.Profiles.Content[i+1].Decode(profile)
+		if err != nil {
+			foundErrors = true
+			profileError := ResourceErrors[Profile]{Resource: profile, Errors: []error{err}}
+			if typeErr, ok := err.(*yaml.TypeError); ok {
+				profileError.Errors = core.StringsToErrors(typeErr.Errors)
+			}
+			profileErrors = append(profileErrors, profileError)
+			continue
+		}
+
+		profiles = append(profiles, *profile)
+	}
+
+	if foundErrors {
+		return profiles, profileErrors
+	}
+
+	return profiles, nil
+}
+
+func (c Config) GetProfile(name string) (*Profile, error) {
+	for _, profile := range c.ProfileList {
+		if name == profile.Name {
+			return &profile, nil
+		}
+	}
+
+	return nil, &core.ProfileNotFound{Name: name}
+}
+
+func (c Config) GetProfileNames() []string {
+	names := []string{}
+	for _, profile := range c.ProfileList {
+		names = append(names, profile.Name)
+	}
+
+	return names
+}
+
+// ApplyProfile overlays the profile on the config. Overlays are applied in
+// the order env, specs, targets and projects, each in the order declared.
+func (c *Config) ApplyProfile(name string) error {
+	profile, err := c.GetProfile(name)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+
+	// Profile env takes precedence over config env
+	envCache, envCacheErrors := ParseEnvCache(profile.Env)
+	errs = append(errs, envCacheErrors...)
+	c.EnvList = MergeEnvs(ParseNodeEnv(profile.Env), c.EnvList)
+	c.EnvCache = MergeEnvCaches(envCache, c.EnvCache)
+
+	for i := 0; i+1 < len(profile.Specs.Content); i += 2 {
+		specName := profile.Specs.Content[i].Value
+		j := slices.IndexFunc(c.SpecList, func(s Spec) bool { return s.Name == specName })
+		if j < 0 {
+			errs = append(errs, &core.SpecNotFound{Name: specName})
+			continue
+		}
+
+		spec := c.SpecList[j]
+		err := profile.Specs.Content[i+1].Decode(&spec)
+		if err == nil {
+			err = spec.validate()
+		}
+		if err != nil {
+			errs = append(errs, overlayErrors(err)...)
+			continue
+		}
+		c.SpecList[j] = spec
+	}
+
+	for i := 0; i+1 < len(profile.Targets.Content); i += 2 {
+		targetName := profile.Targets.Content[i].Value
+		j := slices.IndexFunc(c.TargetList, func(t Target) bool { return t.Name == targetName })
+		if j < 0 {
+			errs = append(errs, &core.TargetNotFound{Name: targetName})
+			continue
+		}
+
+		target := c.TargetList[j]
+		err := profile.Targets.Content[i+1].Decode(&target)
+		if err == nil {
+			err = target.validate()
+		}
+		if err != nil {
+			errs = append(errs, overlayErrors(err)...)
+			continue
+		}
+		c.TargetList[j] = target
+	}
+
+	for i := 0; i+1 < len(profile.Projects.Content); i += 2 {
+		projectName := profile.Projects.Content[i].Value
+		j, err := c.findProject(projectName)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if j < 0 {
+			errs = append(errs, &core.ProjectNotFound{Name: []string{projectName}})
+			continue
+		}
+
+		err = c.overlayProject(&c.ProjectList[j], profile.Projects.Content[i+1])
+		if err != nil {
+			errs = append(errs, overlayErrors(err)...)
+		}
+	}
+
+	if len(errs) > 0 {
+		return FormatErrors(profile, errs)
+	}
+
+	c.Profile = name
+
+	return nil
+}
+
+// overlayProject sets the project fields declared in node. Paths are relative
+// to the main config and env is merged with the project env.
+func (c Config) overlayProject(project *Project, node *yaml.Node) error {
+	p := *project
+	p.Env = yaml.Node{}
+	err := node.Decode(&p)
+	if err != nil {
+		return err
+	}
+
+	// Only the fields the profile sets are interpolated, the other fields were
+	// interpolated when the project was read
+	var overlay Project
+	err = node.Decode(&overlay)
+	if err != nil {
+		return err
+	}
+	overlay.RemoteList = ParseRemotes(overlay.Remotes)
+	overlay.WorktreeList, err = ParseWorktrees(overlay.Worktrees)
+	if err != nil {
+		return err
+	}
+
+	if errs := c.interpolateProject(&overlay); len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	if MappingValue(node, "path") != nil {
+		p.Path = overlay.Path
+	}
+	if MappingValue(node, "url") != nil {
+		p.URL = overlay.URL
+	}
+	if MappingValue(node, "branch") != nil {
+		p.Branch = overlay.Branch
+	}
+	if MappingValue(node, "clone") != nil {
+		p.Clone = overlay.Clone
+	}
+	if MappingValue(node, "remotes") != nil {
+		p.RemoteList = overlay.RemoteList
+	}
+	if MappingValue(node, "worktrees") != nil {
+		p.WorktreeList = overlay.WorktreeList
+	}
+
+	if MappingValue(node, "path") != nil {
+		p.Path, err = core.GetAbsolutePath(c.Dir, p.Path, p.Name)
+		if err != nil {
+			return err
+		}
+
+		p.RelPath, err = core.GetRelativePath(c.Dir, p.Path)
+		if err != nil {
+			return err
+		}
+	}
+
+	if MappingValue(node, "env") != nil {
+		envCache, errs := ParseEnvCache(p.Env)
+		if len(errs) > 0 {
+			return errors.Join(errs...)
+		}
+		p.EnvList = MergeEnvs(ParseNodeEnv(p.Env), p.EnvList)
+		p.EnvCache = MergeEnvCaches(envCache, p.EnvCache)
+	}
+
+	*project = p
+
+	return nil
+}
+
+func overlayErrors(err error) []error {
+	if typeErr, ok := err.(*yaml.TypeError); ok {
+		return core.StringsToErrors(typeErr.Errors)
+	}
+
+	return []error{err}
+}
@@ -0,0 +1,139 @@
+package dao
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const profileTestConfig = `
+env:
+  MODE: laptop
+
+specs:
+  default:
+    output: table
+
+projects:
+  api:
+    tags: [backend]
+    env:
+      PORT: "8080"
+
+profiles:
+  ci:
+    env:
+      MODE: ci
+    specs:
+      default:
+        parallel: true
+    targets:
+      default:
+        all: true
+    projects:
+      api:
+        branch: main
+        env:
+          PORT: "9090"
+
+  broken:
+    specs:
+      missing:
+        parallel: true
+`
+
+func TestProfile_ApplyProfile(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"mani.yaml": profileTestConfig})
+
+	t.Setenv("MANI_PROFILE", "ci")
+	config, err := ReadConfig(filepath.Join(dir, "mani.yaml"), "", "", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if config.Profile != "ci" {
+		t.Errorf("expected active profile `ci`, got `%s`", config.Profile)
+	}
+
+	if !reflect.DeepEqual(config.EnvList, []string{"MODE=ci"}) {
+		t.Errorf("expected profile env to override config env, got %v", config.EnvList)
+	}
+
+	spec, _ := config.GetSpec("default")
+	if spec.Output != "table" || !spec.Parallel {
+		t.Errorf("expected spec output `table` and parallel, got %+v", spec)
+	}
+
+	target, _ := config.GetTarget("default")
+	if !target.All {
+		t.Errorf("expected target all to be set")
+	}
+
+	project, _ := config.GetProject("api")
+	if project.Branch != "main" || !reflect.DeepEqual(project.Tags, []string{"backend"}) {
+		t.Errorf("expected branch `main` and tags [backend], got %+v", project)
+	}
+	if !reflect.DeepEqual(project.EnvList, []string{"PORT=9090"}) {
+		t.Errorf("expected profile project env, got %v", project.EnvList)
+	}
+
+	// Flag takes precedence over env
+	_, err = ReadConfig(filepath.Join(dir, "mani.yaml"), "", "broken", false)
+	if err == nil || !strings.Contains(err.Error(), "cannot find spec `missing`") {
+		t.Errorf("expected missing spec error, got %v", err)
+	}
+}
+
+func TestProfile_Interpolation(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"mani.yaml": `
+env:
+  HOST: github.com
+
+import:
+  - imports/${HOST}.yaml
+
+projects:
+  api:
+    url: https://${HOST}/org/api.git
+    clone: git clone ${LITERAL}
+
+profiles:
+  ci:
+    env:
+      HOST: gitlab.com
+    projects:
+      api:
+        branch: ${HOST}
+`,
+		"imports/github.com.yaml": "projects:\n  github:\n",
+		"imports/gitlab.com.yaml": "projects:\n  gitlab:\n",
+	})
+	t.Setenv("LITERAL", "${SECRET}")
+	t.Setenv("SECRET", "expanded twice")
+
+	config, err := ReadConfig(filepath.Join(dir, "mani.yaml"), "", "ci", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	project, err := config.GetProject("api")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if project.URL != "https://gitlab.com/org/api.git" || project.Branch != "gitlab.com" {
+		t.Errorf("expected profile env in url and branch, got %+v", project)
+	}
+
+	// Fields the profile doesn't set are not interpolated again
+	if project.Clone != "git clone ${SECRET}" {
+		t.Errorf("expected clone to be interpolated once, got %s", project.Clone)
+	}
+
+	if _, err := config.GetProject("gitlab"); err != nil {
+		t.Errorf("expected import path to use profile env: %v", err)
+	}
+}
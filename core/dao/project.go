@@ -3,10 +3,12 @@ package dao
 import (
 	"bufio"
 	"container/list"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
+	"runtime"
 	"slices"
 	"strings"
 
@@ -26,15 +28,22 @@ type Project struct {
 	Sync         *bool    `yaml:"sync"`
 	Tags         []string `yaml:"tags"`
 	EnvList      []string `yaml:"-"`
+	EnvCache     EnvCache `yaml:"-"`
 	RemoteList   []Remote `yaml:"-"`
 
-	Env          yaml.Node  `yaml:"env"`
-	Remotes      yaml.Node  `yaml:"remotes"`
-	Worktrees    yaml.Node  `yaml:"worktrees"`
-	WorktreeList []Worktree `yaml:"-"`
-	context      string
-	contextLine  int
-	RelPath      string
+	Env           yaml.Node  `yaml:"env"`
+	EnvFiles      yaml.Node  `yaml:"env_files"`
+	EnvFileList   []EnvFile  `yaml:"-"`
+	Remotes       yaml.Node  `yaml:"remotes"`
+	Worktrees     yaml.Node  `yaml:"worktrees"`
+	WorktreeList  []Worktree `yaml:"-"`
+	Tasks         yaml.Node  `yaml:"tasks"`
+	TaskOverrides []Task     `yaml:"-"` // tasks with a project specific implementation
+	Extends       []string   `yaml:"-"` // project templates the project inherits from
+	Namespace     string     `yaml:"-"` // namespace of the import the project is declared in, the name is prefixed with it
+	context       string
+	contextLine   int
+	RelPath       string
 }
 
 type Remote struct {
@@ -91,6 +100,43 @@ func (p Project) GetValue(key string, _ int) string {
 	}
 }
 
+// ParseProjectsEnv evaluates the env of the projects in parallel, at most one
+// project per CPU at a time
+func ParseProjectsEnv(projects []Project) error {
+	errs := make([]error, len(projects))
+
+	wg := core.NewSizedWaitGroup(uint32(runtime.NumCPU()))
+	for i := range projects {
+		wg.Add()
+		go func() {
+			defer wg.Done()
+			errs[i] = projects[i].parseEnv()
+		}()
+	}
+
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// parseEnv reads the env files of the project and evaluates its env. Env files
+// are relative to the project directory, so they are skipped for projects which
+// are not cloned yet.
+func (p *Project) parseEnv() error {
+	if _, err := os.Stat(p.Path); err == nil {
+		envFiles, errs := ParseEnvFiles(p.EnvFiles, p.Path)
+		if len(errs) > 0 {
+			return FormatErrors(p, errs)
+		}
+		p.EnvFileList = envFiles
+		p.EnvList = MergeEnvs(p.EnvList, EnvFilesEnv(envFiles))
+	}
+
+	var err error
+	p.EnvList, err = EvaluateEnv(p.EnvList, p.EnvCache)
+	return err
+}
+
 func (c *Config) GetProjectList() ([]Project, []ResourceErrors[Project]) {
 	var projects []Project
 	count := len(c.Projects.Content)
@@ -103,26 +149,42 @@ func (c *Config) GetProjectList() ([]Project, []ResourceErrors[Project]) {
 			contextLine: c.Projects.Content[i].Line,
 		}
 
-		err := c.Projects.Content[i+1].Decode(project)
+		node, extends, err := c.extendProjectNode(c.Projects.Content[i+1], nil)
+		if err == nil {
+			err = node.Decode(project)
+		}
 		if err != nil {
 			foundErrors = true
-			projectError := ResourceErrors[Project]{Resource: project, Errors: core.StringsToErrors(err.(*yaml.TypeError).Errors)}
+			projectError := ResourceErrors[Project]{Resource: project, Errors: []error{err}}
+			if typeErr, ok := err.(*yaml.TypeError); ok {
+				projectError.Errors = core.StringsToErrors(typeErr.Errors)
+			}
 			projectErrors = append(projectErrors, projectError)
 			continue
 		}
+		project.Extends = extends
 
 		project.Name = c.Projects.Content[i].Value
 
-		// Add absolute and relative path for each project
-		project.Path, err = core.GetAbsolutePath(c.Dir, project.Path, project.Name)
+		project.RemoteList = ParseRemotes(project.Remotes)
+
+		project.WorktreeList, err = ParseWorktrees(project.Worktrees)
 		if err != nil {
 			foundErrors = true
-			projectError := ResourceErrors[Project]{Resource: project, Errors: core.StringsToErrors(err.(*yaml.TypeError).Errors)}
+			projectError := ResourceErrors[Project]{Resource: project, Errors: []error{err}}
 			projectErrors = append(projectErrors, projectError)
 			continue
 		}
 
-		project.RelPath, err = core.GetRelativePath(c.Dir, project.Path)
+		// Replace ${VAR} before resolving the path
+		if errs := c.interpolateProject(project); len(errs) > 0 {
+			foundErrors = true
+			projectErrors = append(projectErrors, ResourceErrors[Project]{Resource: project, Errors: errs})
+			continue
+		}
+
+		// Add absolute and relative path for each project
+		project.Path, err = core.GetAbsolutePath(c.Dir, project.Path, project.Name)
 		if err != nil {
 			foundErrors = true
 			projectError := ResourceErrors[Project]{Resource: project, Errors: core.StringsToErrors(err.(*yaml.TypeError).Errors)}
@@ -130,28 +192,23 @@ func (c *Config) GetProjectList() ([]Project, []ResourceErrors[Project]) {
 			continue
 		}
 
-		envList := []string{}
-		projectEnvs, err := EvaluateEnv(ParseNodeEnv(project.Env))
+		project.RelPath, err = core.GetRelativePath(c.Dir, project.Path)
 		if err != nil {
 			foundErrors = true
 			projectError := ResourceErrors[Project]{Resource: project, Errors: core.StringsToErrors(err.(*yaml.TypeError).Errors)}
 			projectErrors = append(projectErrors, projectError)
 			continue
 		}
-		envList = append(envList, projectEnvs...)
-		project.EnvList = envList
 
-		projectRemotes := ParseRemotes(project.Remotes)
-		project.RemoteList = projectRemotes
-
-		projectWorktrees, err := ParseWorktrees(project.Worktrees)
-		if err != nil {
+		// Env and env files are read with ParseProjectsEnv, only for the projects used
+		project.EnvList = ParseNodeEnv(project.Env)
+		envCache, errs := ParseEnvCache(project.Env)
+		if len(errs) > 0 {
 			foundErrors = true
-			projectError := ResourceErrors[Project]{Resource: project, Errors: []error{err}}
-			projectErrors = append(projectErrors, projectError)
+			projectErrors = append(projectErrors, ResourceErrors[Project]{Resource: project, Errors: errs})
 			continue
 		}
-		project.WorktreeList = projectWorktrees
+		project.EnvCache = envCache
 
 		projects = append(projects, *project)
 	}
@@ -304,14 +361,120 @@ func (c Config) FilterProjects(
 	return finalProjects, nil
 }
 
+// GetProject returns the project with the given name, namespaced projects can
+// also be referenced without namespace if the name is unambiguous
 func (c Config) GetProject(name string) (*Project, error) {
-	for _, project := range c.ProjectList {
-		if name == project.Name {
-			return &project, nil
+	i, err := c.findProject(name)
+	if err != nil {
+		return nil, err
+	}
+	if i < 0 {
+		return nil, &core.ProjectNotFound{Name: []string{name}}
+	}
+
+	project := c.ProjectList[i]
+	return &project, nil
+}
+
+func (c Config) getProjectByPath(path string) *Project {
+	for i := range c.ProjectList {
+		if filepath.Clean(c.ProjectList[i].Path) == filepath.Clean(path) {
+			return &c.ProjectList[i]
 		}
 	}
 
-	return nil, &core.ProjectNotFound{Name: []string{name}}
+	return nil
+}
+
+// Check if worktree path (absolute) is declared for the project
+func (p Project) hasWorktree(path string) bool {
+	for _, wt := range p.WorktreeList {
+		wtPath := wt.Path
+		if !filepath.IsAbs(wtPath) {
+			wtPath = filepath.Join(p.Path, wtPath)
+		}
+
+		if filepath.Clean(wtPath) == filepath.Clean(path) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AddProjectWorktree appends a worktree to the project in the config file that declares it
+func (c Config) AddProjectWorktree(name string, wt Worktree) error {
+	project, err := c.GetProject(name)
+	if err != nil {
+		return err
+	}
+
+	f, err := ReadYAMLFile(project.context)
+	if err != nil {
+		return err
+	}
+
+	projectNode := MappingValue(f.Section("projects"), name)
+	if projectNode == nil {
+		return &core.ProjectNotFound{Name: []string{name}}
+	}
+
+	// Shorthand project definition without any fields, `project:`
+	if projectNode.Kind != yaml.MappingNode {
+		*projectNode = yaml.Node{Kind: yaml.MappingNode}
+	}
+
+	worktrees := MappingValue(projectNode, "worktrees")
+	if worktrees == nil || worktrees.Kind != yaml.SequenceNode {
+		worktrees = &yaml.Node{Kind: yaml.SequenceNode}
+		SetMappingValue(projectNode, "worktrees", worktrees)
+	}
+	worktrees.Content = append(worktrees.Content, WorktreeNode(wt))
+
+	return f.Write()
+}
+
+// RemoveProjectWorktree removes a worktree from the project in the config file that declares it
+func (c Config) RemoveProjectWorktree(name string, path string) error {
+	project, err := c.GetProject(name)
+	if err != nil {
+		return err
+	}
+
+	f, err := ReadYAMLFile(project.context)
+	if err != nil {
+		return err
+	}
+
+	projectNode := MappingValue(f.Section("projects"), name)
+	worktrees := MappingValue(projectNode, "worktrees")
+	if worktrees == nil || worktrees.Kind != yaml.SequenceNode {
+		return &core.WorktreeNotFound{Project: name, Path: path}
+	}
+
+	found := false
+	for i, node := range worktrees.Content {
+		wtPath := MappingValue(node, "path")
+		if wtPath != nil && filepath.Clean(wtPath.Value) == filepath.Clean(path) {
+			worktrees.Content = append(worktrees.Content[:i], worktrees.Content[i+1:]...)
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		return &core.WorktreeNotFound{Project: name, Path: path}
+	}
+
+	if len(worktrees.Content) == 0 {
+		RemoveMappingKey(projectNode, "worktrees")
+		// Project without any fields, `project:`
+		if len(projectNode.Content) == 0 {
+			*projectNode = yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Line: projectNode.Line, Column: projectNode.Column}
+		}
+	}
+
+	return f.Write()
 }
 
 func (c Config) GetProjectsByName(projectNames []string) ([]Project, error) {
@@ -323,11 +486,13 @@ func (c Config) GetProjectsByName(projectNames []string) ([]Project, error) {
 	}
 
 	for _, v := range projectNames {
-		for _, p := range c.ProjectList {
-			if v == p.Name {
-				foundProjectNames[p.Name] = true
-				matchedProjects = append(matchedProjects, p)
-			}
+		i, err := c.findProject(v)
+		if err != nil {
+			return []Project{}, err
+		}
+		if i >= 0 {
+			foundProjectNames[v] = true
+			matchedProjects = append(matchedProjects, c.ProjectList[i])
 		}
 	}
 
@@ -724,6 +889,62 @@ func FindVCSystems(rootPath string) ([]Project, error) {
 	return projects, nil
 }
 
+// GetOrphanedProjects returns git repositories found below the config directory
+// which are not declared as projects or worktrees. Repositories located inside a
+// declared project, or inside any of the excluded directories, are ignored.
+func (c Config) GetOrphanedProjects(excludeDirs []string) ([]Project, error) {
+	found, err := FindVCSystems(c.Dir)
+	if err != nil {
+		return []Project{}, err
+	}
+
+	var declared []string
+	for _, project := range c.ProjectList {
+		declared = append(declared, filepath.Clean(project.Path))
+		for _, wt := range project.WorktreeList {
+			if filepath.IsAbs(wt.Path) {
+				declared = append(declared, filepath.Clean(wt.Path))
+			} else {
+				declared = append(declared, filepath.Join(project.Path, wt.Path))
+			}
+		}
+	}
+
+	isBelow := func(path string, dir string) bool {
+		rel, err := filepath.Rel(dir, path)
+		return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(os.PathSeparator))
+	}
+
+	orphans := []Project{}
+out:
+	for _, p := range found {
+		absPath := filepath.Join(c.Dir, p.Path)
+
+		for _, dir := range excludeDirs {
+			if isBelow(absPath, filepath.Clean(dir)) {
+				continue out
+			}
+		}
+
+		for _, dir := range declared {
+			// The config directory itself may be a project, everything is below it
+			if dir == c.Dir {
+				continue
+			}
+
+			if isBelow(absPath, dir) {
+				continue out
+			}
+		}
+
+		p.RelPath = p.Path
+		p.Path = absPath
+		orphans = append(orphans, p)
+	}
+
+	return orphans, nil
+}
+
 func UpdateProjectsToGitignore(projectNames []string, gitignoreFilename string) (err error) {
 	l := list.New()
 	gitignoreFile, err := os.OpenFile(gitignoreFilename, os.O_RDWR, 0644)
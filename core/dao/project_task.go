@@ -0,0 +1,278 @@
+package dao
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/alajmo/mani/core"
+)
+
+const (
+	// PROJECT_OVERRIDES_FILE is read from each trusted project directory for
+	// task overrides
+	PROJECT_OVERRIDES_FILE = ".mani.yaml"
+
+	// DEFAULT_PROJECT_TASKS_FILE is read from each trusted project directory
+	// for task overrides and tasks of its own
+	DEFAULT_PROJECT_TASKS_FILE = "mani-tasks.yaml"
+)
+
+// ProjectTasks configures which projects may declare tasks in their directory
+type ProjectTasks struct {
+	File            string   `yaml:"file,omitempty"`
+	TrustedProjects []string `yaml:"trusted_projects,omitempty"` // project names, `*` trusts all projects
+	TrustedTags     []string `yaml:"trusted_tags,omitempty"`
+}
+
+// TaskSource is where a project declares its own implementation of a task
+type TaskSource struct {
+	Project string
+	Context string
+	Line    int
+}
+
+func (s TaskSource) String() string {
+	if s.Line > 0 {
+		return fmt.Sprintf("%s:%d", s.Context, s.Line)
+	}
+	return s.Context
+}
+
+// How tasks not declared in the config are handled
+const (
+	unknownTaskError = iota
+	unknownTaskSkip
+	unknownTaskAdd
+)
+
+// GetTask returns the project's override of the task, or the task itself if
+// the project doesn't override it
+func (p Project) GetTask(task Task) Task {
+	for _, override := range p.TaskOverrides {
+		if override.Name == task.Name {
+			return override
+		}
+	}
+
+	return task
+}
+
+// HasTask returns true if the project declares its own implementation of the task
+func (p Project) HasTask(name string) bool {
+	return slices.ContainsFunc(p.TaskOverrides, func(t Task) bool { return t.Name == name })
+}
+
+// IsTrusted returns true if the project may declare tasks in its directory
+func (c Config) IsTrusted(project Project) bool {
+	for _, name := range c.ProjectTasks.TrustedProjects {
+		if name == "*" || name == project.Name || name == unqualifyName(project.Namespace, project.Name) {
+			return true
+		}
+	}
+
+	for _, tag := range project.Tags {
+		if slices.Contains(c.ProjectTasks.TrustedTags, tag) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseProjectTasks parses the tasks declared by each project, in order of
+// precedence:
+//
+//  1. the project's `tasks` in the config
+//  2. the project tasks file (mani-tasks.yaml) in the project directory
+//  3. the .mani.yaml file in the project directory
+//
+// Files in the project directory are only read for trusted projects. An
+// override inherits the fields it doesn't set from the task in the config,
+// except that setting cmd or commands replaces both. Tasks only declared in a
+// project tasks file are added to the config and run only in the projects
+// declaring them.
+func (c *Config) parseProjectTasks() []ResourceErrors[Task] {
+	var taskErrors []ResourceErrors[Task]
+
+	file := c.ProjectTasks.File
+	if file == "" {
+		file = DEFAULT_PROJECT_TASKS_FILE
+	}
+
+	for i := range c.ProjectList {
+		project := &c.ProjectList[i]
+		project.TaskOverrides = nil
+
+		if c.IsTrusted(*project) {
+			// Tasks in .mani.yaml which are not declared in the config are
+			// ignored, the file can be a mani config of its own
+			files := []struct {
+				path    string
+				unknown int
+			}{
+				{path: filepath.Join(project.Path, PROJECT_OVERRIDES_FILE), unknown: unknownTaskSkip},
+				{path: filepath.Join(project.Path, file), unknown: unknownTaskAdd},
+			}
+
+			for _, f := range files {
+				if f.path == c.Path {
+					continue
+				}
+
+				node, err := readProjectTasksFile(f.path)
+				if err != nil {
+					taskErrors = append(taskErrors, ResourceErrors[Task]{
+						Resource: &Task{Name: project.Name, context: f.path, contextLine: -1},
+						Errors:   []error{err},
+					})
+				} else if node != nil {
+					taskErrors = append(taskErrors, c.parseTaskOverrides(project, node, f.path, f.unknown)...)
+				}
+			}
+		}
+
+		taskErrors = append(taskErrors, c.parseTaskOverrides(project, &project.Tasks, project.context, unknownTaskError)...)
+	}
+
+	return taskErrors
+}
+
+// readProjectTasksFile returns the tasks section of the file, or nil if the
+// file doesn't exist
+func readProjectTasksFile(path string) (*yaml.Node, error) {
+	dat, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var fragment struct {
+		Tasks yaml.Node `yaml:"tasks"`
+	}
+	err = decodeConfigFile(path, dat, &fragment)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fragment.Tasks, nil
+}
+
+func (c *Config) parseTaskOverrides(project *Project, node *yaml.Node, context string, unknown int) []ResourceErrors[Task] {
+	var taskErrors []ResourceErrors[Task]
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		override := &Task{
+			Name:        node.Content[i].Value,
+			context:     context,
+			contextLine: node.Content[i].Line,
+			declared:    declaredKeys(node.Content[i+1]),
+		}
+		taskError := ResourceErrors[Task]{Resource: override}
+
+		// Shorthand definition: test: make check
+		if node.Content[i+1].Kind == yaml.ScalarNode {
+			override.Cmd = node.Content[i+1].Value
+		} else {
+			err := node.Content[i+1].Decode(override)
+			if err != nil {
+				taskError.Errors = append(taskError.Errors, overlayErrors(err)...)
+				taskErrors = append(taskErrors, taskError)
+				continue
+			}
+		}
+
+		_, errs := ParseEnvCache(override.Env)
+		if len(errs) > 0 {
+			taskError.Errors = append(taskError.Errors, errs...)
+			taskErrors = append(taskErrors, taskError)
+			continue
+		}
+
+		// Env files are relative to the file declaring the override
+		envFiles, errs := ParseEnvFiles(override.EnvFiles, filepath.Dir(context))
+		if len(errs) > 0 {
+			taskError.Errors = append(taskError.Errors, errs...)
+			taskErrors = append(taskErrors, taskError)
+			continue
+		}
+		override.EnvFileList = envFiles
+
+		j, err := c.findTask(override.Name, project.Namespace)
+		if err == nil && j < 0 {
+			switch unknown {
+			case unknownTaskSkip:
+				continue
+			case unknownTaskAdd:
+				j = c.addProjectTask(*override)
+			default:
+				err = &core.TaskNotFound{Name: []string{override.Name}}
+			}
+		}
+		if err != nil {
+			taskError.Errors = append(taskError.Errors, err)
+			taskErrors = append(taskErrors, taskError)
+			continue
+		}
+
+		global := c.TaskList[j]
+		override.Name = global.Name
+		override.Namespace = global.Namespace
+		override.Extends = ""
+		override.inherit(global)
+
+		override.ParseTask(*c, &taskError)
+		if len(taskError.Errors) > 0 {
+			taskErrors = append(taskErrors, taskError)
+			continue
+		}
+
+		project.setTaskOverride(*override)
+
+		source := TaskSource{Project: project.Name, Context: context, Line: override.contextLine}
+		c.TaskList[j].Sources = slices.DeleteFunc(c.TaskList[j].Sources, func(s TaskSource) bool { return s.Project == project.Name })
+		c.TaskList[j].Sources = append(c.TaskList[j].Sources, source)
+		if c.TaskList[j].FromProjects && !slices.Contains(c.TaskList[j].TargetData.Projects, project.Name) {
+			c.TaskList[j].TargetData.Projects = append(c.TaskList[j].TargetData.Projects, project.Name)
+		}
+	}
+
+	return taskErrors
+}
+
+// addProjectTask adds a task declared only in project tasks files to the
+// config, so it can be listed and run. It has no command of its own, each
+// project runs its own implementation.
+func (c *Config) addProjectTask(task Task) int {
+	projectTask := Task{
+		Name:         task.Name,
+		Desc:         task.Desc,
+		FromProjects: true,
+		context:      task.context,
+		contextLine:  task.contextLine,
+	}
+
+	taskError := ResourceErrors[Task]{Resource: &projectTask}
+	projectTask.ParseTask(*c, &taskError)
+	projectTask.TargetData = Target{Projects: []string{}}
+
+	c.TaskList = append(c.TaskList, projectTask)
+
+	return len(c.TaskList) - 1
+}
+
+func (p *Project) setTaskOverride(task Task) {
+	for i := range p.TaskOverrides {
+		if p.TaskOverrides[i].Name == task.Name {
+			p.TaskOverrides[i] = task
+			return
+		}
+	}
+
+	p.TaskOverrides = append(p.TaskOverrides, task)
+}
@@ -0,0 +1,146 @@
+package dao
+
+import (
+	"slices"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/alajmo/mani/core"
+)
+
+// extendProjectNode returns the project node with the fields of the templates
+// in `extends` merged in, and the names of the templates. Templates are applied
+// in order, later templates and the project itself override earlier values:
+//
+//   - tags are appended, skipping duplicates
+//   - env, remotes and tasks are merged by key
+//   - all other fields are replaced
+//
+// Templates are looked up in the project's own config first, then in all
+// loaded configs. Templates can extend other templates, chain holds the
+// templates being resolved to detect cycles.
+func (c Config) extendProjectNode(node *yaml.Node, chain []string) (*yaml.Node, []string, error) {
+	value := MappingValue(node, "extends")
+	if value == nil {
+		return node, nil, nil
+	}
+
+	var names []string
+	if value.Kind == yaml.ScalarNode {
+		if value.Value != "" {
+			names = []string{value.Value}
+		}
+	} else {
+		err := value.Decode(&names)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	merged := &yaml.Node{Kind: yaml.MappingNode}
+	for _, name := range names {
+		if slices.Contains(chain, name) {
+			return nil, nil, &core.ProjectTemplateCycle{Names: append(slices.Clone(chain), name)}
+		}
+
+		template := MappingValue(&c.ProjectTemplates, name)
+		if template == nil {
+			template = MappingValue(&c.projectTemplates, name)
+		}
+		if template == nil {
+			return nil, nil, &core.ProjectTemplateNotFound{Name: name}
+		}
+
+		resolved, _, err := c.extendProjectNode(template, append(slices.Clone(chain), name))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		merged = mergeProjectNodes(merged, resolved)
+	}
+
+	return mergeProjectNodes(merged, node), names, nil
+}
+
+// mergeProjectNodes returns a new mapping node with the fields of override
+// merged into base
+func mergeProjectNodes(base *yaml.Node, override *yaml.Node) *yaml.Node {
+	out := &yaml.Node{Kind: yaml.MappingNode, Line: override.Line, Column: override.Column}
+
+	for i := 0; i+1 < len(base.Content); i += 2 {
+		key := base.Content[i].Value
+		if key == "extends" {
+			continue
+		}
+
+		value := base.Content[i+1]
+		if o := MappingValue(override, key); o != nil {
+			switch key {
+			case "tags":
+				value = mergeSequenceNodes(value, o)
+			case "env", "remotes", "tasks":
+				value = mergeMappingNodes(value, o)
+			default:
+				value = o
+			}
+		}
+
+		out.Content = append(out.Content, base.Content[i], value)
+	}
+
+	if override.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(override.Content); i += 2 {
+			key := override.Content[i].Value
+			if key == "extends" || MappingValue(base, key) != nil {
+				continue
+			}
+
+			out.Content = append(out.Content, override.Content[i], override.Content[i+1])
+		}
+	}
+
+	return out
+}
+
+func mergeSequenceNodes(base *yaml.Node, override *yaml.Node) *yaml.Node {
+	if base.Kind != yaml.SequenceNode || override.Kind != yaml.SequenceNode {
+		return override
+	}
+
+	out := &yaml.Node{Kind: yaml.SequenceNode, Style: override.Style, Line: override.Line, Column: override.Column}
+	seen := make(map[string]bool)
+	for _, item := range slices.Concat(base.Content, override.Content) {
+		if item.Kind == yaml.ScalarNode {
+			if seen[item.Value] {
+				continue
+			}
+			seen[item.Value] = true
+		}
+		out.Content = append(out.Content, item)
+	}
+
+	return out
+}
+
+func mergeMappingNodes(base *yaml.Node, override *yaml.Node) *yaml.Node {
+	if base.Kind != yaml.MappingNode || override.Kind != yaml.MappingNode {
+		return override
+	}
+
+	out := &yaml.Node{Kind: yaml.MappingNode, Line: override.Line, Column: override.Column}
+	for i := 0; i+1 < len(base.Content); i += 2 {
+		value := base.Content[i+1]
+		if o := MappingValue(override, base.Content[i].Value); o != nil {
+			value = o
+		}
+		out.Content = append(out.Content, base.Content[i], value)
+	}
+
+	for i := 0; i+1 < len(override.Content); i += 2 {
+		if MappingValue(base, override.Content[i].Value) == nil {
+			out.Content = append(out.Content, override.Content[i], override.Content[i+1])
+		}
+	}
+
+	return out
+}
@@ -1,6 +1,10 @@
 package dao
 
 import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
 	"gopkg.in/yaml.v3"
@@ -522,3 +526,409 @@ func TestProject_GetValue_Worktrees(t *testing.T) {
 		})
 	}
 }
+
+func TestProject_GetOrphanedProjects(t *testing.T) {
+	dir := t.TempDir()
+	for _, p := range []string{
+		"declared/.git",
+		"declared/nested/.git",
+		"orphan/.git",
+		"group/orphan-b/.git",
+		"archive/old/.git",
+	} {
+		if err := os.MkdirAll(filepath.Join(dir, p), os.ModePerm); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+	}
+
+	config := Config{
+		Dir: dir,
+		ProjectList: []Project{
+			{Name: "root", Path: dir},
+			{Name: "declared", Path: filepath.Join(dir, "declared")},
+		},
+	}
+
+	orphans, err := config.GetOrphanedProjects([]string{filepath.Join(dir, "archive")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got []string
+	for _, o := range orphans {
+		got = append(got, o.RelPath)
+	}
+
+	expected := []string{filepath.Join("group", "orphan-b"), "orphan"}
+	if !equalStringSlices(got, expected) {
+		t.Errorf("expected orphans %v, got %v", expected, got)
+	}
+}
+
+func TestProject_AddRemoveProjectWorktree(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mani.yaml")
+	src := "projects:\n  app:\n\n  lib:\n    url: https://example.com/lib.git\n"
+	if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	config := Config{
+		ProjectList: []Project{
+			{Name: "app", context: path},
+			{Name: "lib", context: path},
+		},
+	}
+
+	if err := config.AddProjectWorktree("app", Worktree{Path: "feature", Branch: "feature"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "projects:\n  app:\n    worktrees:\n      - path: feature\n        branch: feature\n\n  lib:\n    url: https://example.com/lib.git\n"
+	got, _ := os.ReadFile(path)
+	if string(got) != expected {
+		t.Errorf("after add expected:\n%s\ngot:\n%s", expected, got)
+	}
+
+	if err := config.RemoveProjectWorktree("app", "feature"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, _ = os.ReadFile(path)
+	if string(got) != src {
+		t.Errorf("after remove expected:\n%s\ngot:\n%s", src, got)
+	}
+
+	err := config.RemoveProjectWorktree("lib", "feature")
+	if _, ok := err.(*core.WorktreeNotFound); !ok {
+		t.Errorf("expected WorktreeNotFound, got %v", err)
+	}
+}
+
+func TestProject_ExtendTemplates(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"mani.yaml": `
+project_templates:
+  base:
+    tags: [managed]
+    single_branch: true
+    env:
+      LOG: info
+  service:
+    extends: base
+    branch: main
+    tags: [service]
+    env:
+      PORT: "80"
+
+projects:
+  api:
+    extends: service
+    branch: develop
+    tags: [api, managed]
+    env:
+      LOG: debug
+`,
+	})
+
+	config, err := ReadConfig(filepath.Join(dir, "mani.yaml"), "", "", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	project, err := config.GetProject("api")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(project.Extends, []string{"service"}) {
+		t.Errorf("expected extends [service], got %v", project.Extends)
+	}
+	if project.Branch != "develop" || !project.IsSingleBranch() {
+		t.Errorf("expected branch `develop` and single branch, got %+v", project)
+	}
+	if !reflect.DeepEqual(project.Tags, []string{"managed", "service", "api"}) {
+		t.Errorf("expected merged tags, got %v", project.Tags)
+	}
+	if !reflect.DeepEqual(project.EnvList, []string{"LOG=debug", "PORT=80"}) {
+		t.Errorf("expected merged env, got %v", project.EnvList)
+	}
+
+	// Cyclic templates
+	writeTestFiles(t, dir, map[string]string{
+		"mani.yaml": "project_templates:\n  a:\n    extends: b\n  b:\n    extends: a\n\nprojects:\n  api:\n    extends: a\n",
+	})
+	_, err = ReadConfig(filepath.Join(dir, "mani.yaml"), "", "", false)
+	if err == nil || !strings.Contains(err.Error(), "circular project template `a` -> `b` -> `a`") {
+		t.Errorf("expected circular template error, got %v", err)
+	}
+
+	// Templates of imported configs, the project's own config takes precedence
+	writeTestFiles(t, dir, map[string]string{
+		"mani.yaml": `
+import:
+  - templates.yaml
+
+project_templates:
+  service:
+    branch: main
+
+projects:
+  api:
+    extends: [base, service]
+`,
+		"templates.yaml": `
+project_templates:
+  base:
+    tags: [managed]
+  service:
+    branch: develop
+
+projects:
+  web:
+    extends: service
+`,
+	})
+	config, err = ReadConfig(filepath.Join(dir, "mani.yaml"), "", "", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for name, expected := range map[string]Project{"api": {Branch: "main", Tags: []string{"managed"}}, "web": {Branch: "develop"}} {
+		project, err := config.GetProject(name)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if project.Branch != expected.Branch || !reflect.DeepEqual(project.Tags, expected.Tags) {
+			t.Errorf("expected project %s with branch %s and tags %v, got %+v", name, expected.Branch, expected.Tags, project)
+		}
+	}
+}
+
+func TestProject_TaskOverrides(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"mani.yaml": `
+project_tasks:
+  trusted_projects: [legacy, web]
+
+projects:
+  api:
+  legacy:
+    tasks:
+      test: make check
+  web:
+
+tasks:
+  test:
+    env:
+      X: x
+    commands:
+      - cmd: go vet
+      - cmd: go test
+`,
+		"api/.mani.yaml":    "tasks:\n  test: rm -rf /\n",
+		"legacy/.mani.yaml": "tasks:\n  test: ignored\n",
+		"web/.mani.yaml":    "tasks:\n  test:\n    env:\n      Y: y\n  own: echo own\n",
+	})
+
+	config, err := ReadConfig(filepath.Join(dir, "mani.yaml"), "", "", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// .mani.yaml is not read from untrusted projects, and the config takes
+	// precedence over it in trusted projects
+	task, _ := config.GetTask("test")
+	expected := map[string]struct {
+		cmd      string
+		commands int
+		env      []string
+	}{
+		"api":    {cmd: "", commands: 2, env: []string{"X=x"}},
+		"legacy": {cmd: "make check", commands: 0, env: []string{"X=x"}},
+		"web":    {cmd: "", commands: 2, env: []string{"X=x", "Y=y"}},
+	}
+
+	for _, project := range config.ProjectList {
+		projectTask := project.GetTask(*task)
+		want := expected[project.Name]
+		if projectTask.Cmd != want.cmd || len(projectTask.Commands) != want.commands {
+			t.Errorf("%s: expected cmd `%s` and %d commands, got `%s` and %d", project.Name, want.cmd, want.commands, projectTask.Cmd, len(projectTask.Commands))
+		}
+		if !reflect.DeepEqual(ParseNodeEnv(projectTask.Env), want.env) {
+			t.Errorf("%s: expected env %v, got %v", project.Name, want.env, ParseNodeEnv(projectTask.Env))
+		}
+	}
+
+	// Overrides of undeclared tasks in the config are errors
+	writeTestFiles(t, dir, map[string]string{
+		"mani.yaml": "projects:\n  api:\n    tasks:\n      missing: echo\n",
+	})
+	_, err = ReadConfig(filepath.Join(dir, "mani.yaml"), "", "", false)
+	if err == nil || !strings.Contains(err.Error(), "cannot find tasks `missing`") {
+		t.Errorf("expected missing task error, got %v", err)
+	}
+}
+
+func TestProject_TasksFile(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"mani.yaml": `
+project_tasks:
+  trusted_tags: [trusted]
+
+projects:
+  api:
+    tags: [trusted]
+  web:
+    tags: [trusted]
+  untrusted:
+
+tasks:
+  test: go test
+`,
+		"api/mani-tasks.yaml":       "tasks:\n  test: make check\n  lint:\n    desc: lint api\n    cmd: golangci-lint run\n",
+		"web/mani-tasks.yaml":       "tasks:\n  lint: npm run lint\n",
+		"untrusted/mani-tasks.yaml": "tasks:\n  test: rm -rf /\n  lint: rm -rf /\n",
+	})
+
+	config, err := ReadConfig(filepath.Join(dir, "mani.yaml"), "", "", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	test, _ := config.GetTask("test")
+	lint, err := config.GetTask("lint")
+	if err != nil {
+		t.Fatalf("expected task `lint` from project tasks files, got %v", err)
+	}
+	if !lint.FromProjects || lint.Cmd != "" || !reflect.DeepEqual(lint.TargetData.Projects, []string{"api", "web"}) {
+		t.Errorf("expected `lint` without cmd targeting api and web, got %+v", lint.TargetData.Projects)
+	}
+
+	expected := map[string][2]string{
+		"api":       {"make check", "golangci-lint run"},
+		"web":       {"go test", "npm run lint"},
+		"untrusted": {"go test", ""},
+	}
+	for _, project := range config.ProjectList {
+		want := expected[project.Name]
+		if cmd := project.GetTask(*test).Cmd; cmd != want[0] {
+			t.Errorf("%s: expected test cmd `%s`, got `%s`", project.Name, want[0], cmd)
+		}
+		if cmd := project.GetTask(*lint).Cmd; cmd != want[1] {
+			t.Errorf("%s: expected lint cmd `%s`, got `%s`", project.Name, want[1], cmd)
+		}
+	}
+
+	if len(test.Sources) != 1 || test.Sources[0].Project != "api" || test.Sources[0].String() != filepath.Join(dir, "api", "mani-tasks.yaml")+":2" {
+		t.Errorf("expected `test` source in api/mani-tasks.yaml:2, got %v", test.Sources)
+	}
+
+	// Running several tasks, lint only runs in the projects declaring it
+	tasks, projects, err := ParseManyTasks([]string{"test", "lint"}, &core.RunFlags{All: true}, &core.SetRunFlags{All: true}, &config)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	commands := map[string][]string{}
+	for i, project := range projects {
+		for _, cmd := range tasks[i].Commands {
+			commands[project.Name] = append(commands[project.Name], cmd.Cmd)
+		}
+	}
+	expectedCommands := map[string][]string{
+		"api":       {"make check", "golangci-lint run"},
+		"web":       {"go test", "npm run lint"},
+		"untrusted": {"go test"},
+	}
+	if !reflect.DeepEqual(commands, expectedCommands) {
+		t.Errorf("expected commands %v, got %v", expectedCommands, commands)
+	}
+
+	_, projects, err = ParseManyTasks([]string{"lint"}, &core.RunFlags{All: true}, &core.SetRunFlags{All: true}, &config)
+	if err != nil || len(projects) != 2 {
+		t.Errorf("expected lint to run in api and web, got %d projects, %v", len(projects), err)
+	}
+
+	// The file name is configurable
+	writeTestFiles(t, dir, map[string]string{
+		"mani.yaml":      "project_tasks:\n  file: tasks.yaml\n  trusted_projects: ['*']\nprojects:\n  api:\n",
+		"api/tasks.yaml": "tasks:\n  build: make\n",
+	})
+	config, err = ReadConfig(filepath.Join(dir, "mani.yaml"), "", "", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := config.GetTask("build"); err != nil {
+		t.Errorf("expected task `build` from tasks.yaml, got %v", err)
+	}
+}
+
+func TestProject_Interpolation(t *testing.T) {
+	t.Setenv("MANI_TEST_ROOT", "src")
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"mani.yaml": `
+import:
+  - ${TEAM}.yaml
+
+env:
+  GIT_HOST: git.example.com
+  TEAM: $(echo team)
+
+projects:
+  api:
+    path: ${MANI_TEST_ROOT}/api
+    url: git@${GIT_HOST}:org/api.git
+    branch: ${TEAM}-main
+    clone: git clone ${GIT_HOST} ${CLONE_DIR}
+    remotes:
+      upstream: https://${GIT_HOST}/upstream/api.git
+    worktrees:
+      - path: ../${TEAM}
+`,
+		"team.yaml": `
+projects:
+  web:
+    url: git@${GIT_HOST}:org/web.git
+`,
+	})
+
+	config, err := ReadConfig(filepath.Join(dir, "mani.yaml"), "", "", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	api, _ := config.GetProject("api")
+	if api.Path != filepath.Join(dir, "src", "api") {
+		t.Errorf("expected path from process env, got %s", api.Path)
+	}
+	if api.URL != "git@git.example.com:org/api.git" || api.Branch != "team-main" {
+		t.Errorf("expected url and branch from config env, got %s and %s", api.URL, api.Branch)
+	}
+	if api.Clone != "git clone git.example.com ${CLONE_DIR}" {
+		t.Errorf("expected unset variables in clone to be kept, got %s", api.Clone)
+	}
+	if api.RemoteList[0].URL != "https://git.example.com/upstream/api.git" {
+		t.Errorf("unexpected remote %s", api.RemoteList[0].URL)
+	}
+	if api.WorktreeList[0].Path != "../team" || api.WorktreeList[0].Branch != "team" {
+		t.Errorf("unexpected worktree %+v", api.WorktreeList[0])
+	}
+
+	// Imported configs use the env of the main config
+	web, _ := config.GetProject("web")
+	if web.URL != "git@git.example.com:org/web.git" {
+		t.Errorf("expected url from main config env, got %s", web.URL)
+	}
+
+	writeTestFiles(t, dir, map[string]string{
+		"mani.yaml": "projects:\n  api:\n    url: git@${MANI_TEST_UNSET}:api.git\n",
+	})
+	_, err = ReadConfig(filepath.Join(dir, "mani.yaml"), "", "", false)
+	if err == nil || !strings.Contains(err.Error(), "env `MANI_TEST_UNSET` is not set") {
+		t.Errorf("expected unset variable error, got %v", err)
+	}
+}
@@ -0,0 +1,254 @@
+package dao
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/alajmo/mani/core"
+)
+
+// Schema is a JSON Schema describing the config, used by editors to validate
+// and complete mani.yaml
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Type                 any                `json:"type,omitempty"` // a type or a list of types
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties any                `json:"additionalProperties,omitempty"` // false or a schema
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Minimum              *int               `json:"minimum,omitempty"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`
+}
+
+// Named colors from the W3C standard, accepted by fg, bg and prefix_colors
+var namedColors = strings.Fields(`
+	aliceblue antiquewhite aqua aquamarine azure beige bisque black blanchedalmond blue
+	blueviolet brown burlywood cadetblue chartreuse chocolate coral cornflowerblue cornsilk
+	crimson cyan darkblue darkcyan darkgoldenrod darkgray darkgreen darkgrey darkkhaki
+	darkmagenta darkolivegreen darkorange darkorchid darkred darksalmon darkseagreen
+	darkslateblue darkslategray darkslategrey darkturquoise darkviolet deeppink deepskyblue
+	dimgray dimgrey dodgerblue firebrick floralwhite forestgreen fuchsia gainsboro ghostwhite
+	gold goldenrod gray green greenyellow grey honeydew hotpink indianred indigo ivory khaki
+	lavender lavenderblush lawngreen lemonchiffon lightblue lightcoral lightcyan
+	lightgoldenrodyellow lightgray lightgreen lightgrey lightpink lightsalmon lightseagreen
+	lightskyblue lightslategray lightslategrey lightsteelblue lightyellow lime limegreen
+	linen magenta maroon mediumaquamarine mediumblue mediumorchid mediumpurple mediumseagreen
+	mediumslateblue mediumspringgreen mediumturquoise mediumvioletred midnightblue mintcream
+	mistyrose moccasin navajowhite navy oldlace olive olivedrab orange orangered orchid
+	palegoldenrod palegreen paleturquoise palevioletred papayawhip peachpuff peru pink plum
+	powderblue purple red rosybrown royalblue saddlebrown salmon sandybrown seagreen seashell
+	sienna silver skyblue slateblue slategray slategrey snow springgreen steelblue tan teal
+	thistle tomato turquoise violet wheat white whitesmoke yellow yellowgreen
+`)
+
+func stringSchema() *Schema {
+	return &Schema{Type: "string"}
+}
+
+func enumSchema(values ...string) *Schema {
+	return &Schema{Type: "string", Enum: values}
+}
+
+func mapSchema(value *Schema) *Schema {
+	return &Schema{Type: "object", AdditionalProperties: value}
+}
+
+func anyOfSchema(schemas ...*Schema) *Schema {
+	return &Schema{AnyOf: schemas}
+}
+
+// colorSchema accepts an empty string, hex, `r,g,b` or a named color
+func colorSchema() *Schema {
+	return anyOfSchema(
+		&Schema{Type: "string", Pattern: `^(|#[0-9a-fA-F]{3}|#[0-9a-fA-F]{6}|\d{1,3},\d{1,3},\d{1,3})$`},
+		enumSchema(namedColors...),
+	)
+}
+
+type schemaBuilder struct {
+	defs    map[string]*Schema
+	fields  map[string]*Schema
+	pending []reflect.Type // referenced structs without a definition
+}
+
+// fieldSchemas replaces the schema of struct fields, keyed by `<type>.<key>`.
+// yaml.Node fields must be declared here since their type is only known when
+// the node is parsed.
+func (b *schemaBuilder) fieldSchemas() map[string]*Schema {
+	env := mapSchema(anyOfSchema(
+		&Schema{Type: []string{"string", "number", "boolean", "null"}},
+		&Schema{
+			Type:                 "object",
+			Properties:           map[string]*Schema{"value": stringSchema(), "cache": stringSchema()},
+			Required:             []string{"value"},
+			AdditionalProperties: false,
+		},
+	))
+	envFiles := anyOfSchema(
+		stringSchema(),
+		&Schema{Type: "array", Items: anyOfSchema(stringSchema(), b.ref(reflect.TypeFor[EnvFile]()))},
+	)
+	tasks := mapSchema(anyOfSchema(stringSchema(), b.ref(reflect.TypeFor[Task]())))
+	projects := mapSchema(b.ref(reflect.TypeFor[Project]()))
+	specs := mapSchema(b.ref(reflect.TypeFor[Spec]()))
+	targets := mapSchema(b.ref(reflect.TypeFor[Target]()))
+	imports := &Schema{Type: "array", Items: anyOfSchema(
+		stringSchema(),
+		&Schema{
+			Type:                 "object",
+			Properties:           map[string]*Schema{"path": stringSchema(), "as": stringSchema()},
+			Required:             []string{"path"},
+			AdditionalProperties: false,
+		},
+	)}
+
+	return map[string]*Schema{
+		"Config.env":               env,
+		"Config.env_files":         envFiles,
+		"Config.import":            imports,
+		"Config.themes":            mapSchema(b.ref(reflect.TypeFor[Theme]())),
+		"Config.specs":             specs,
+		"Config.targets":           targets,
+		"Config.projects":          projects,
+		"Config.tasks":             tasks,
+		"Config.profiles":          mapSchema(b.ref(reflect.TypeFor[Profile]())),
+		"Config.secrets":           mapSchema(b.ref(reflect.TypeFor[Secret]())),
+		"Config.project_templates": projects,
+
+		"Project.env":       env,
+		"Project.env_files": envFiles,
+		"Project.remotes":   mapSchema(stringSchema()),
+		"Project.worktrees": &Schema{Type: "array", Items: b.ref(reflect.TypeFor[Worktree]())},
+		"Project.tasks":     tasks,
+
+		"Task.env":       env,
+		"Task.env_files": envFiles,
+		"Task.spec":      anyOfSchema(stringSchema(), b.ref(reflect.TypeFor[Spec]())),
+		"Task.target":    anyOfSchema(stringSchema(), b.ref(reflect.TypeFor[Target]())),
+		"Task.theme":     anyOfSchema(stringSchema(), b.ref(reflect.TypeFor[Theme]())),
+		"Command.env":    env,
+
+		"Profile.env":      env,
+		"Profile.specs":    specs,
+		"Profile.targets":  targets,
+		"Profile.projects": projects,
+
+		"Spec.output":          enumSchema("stream", "table", "html", "markdown"),
+		"Table.style":          enumSchema("ascii", "light", "bold", "double", "rounded"),
+		"Tree.style":           enumSchema("ascii", "light", "bullet-flower", "bullet-square", "bullet-star", "bullet-triangle", "bold", "double", "rounded", "markdown"),
+		"Stream.prefix_colors": &Schema{Type: "array", Items: colorSchema()},
+		"ColorOptions.fg":      colorSchema(),
+		"ColorOptions.bg":      colorSchema(),
+		"ColorOptions.attr":    enumSchema("", "bold", "italic", "underline"),
+		"ColorOptions.align":   enumSchema("", "left", "center", "right"),
+		"ColorOptions.format":  enumSchema("", "lower", "title", "upper"),
+	}
+}
+
+// GenerateSchema returns the JSON Schema of the config, generated from the
+// config resources
+func GenerateSchema() *Schema {
+	b := &schemaBuilder{defs: map[string]*Schema{}}
+	b.fields = b.fieldSchemas()
+	root := b.object(reflect.TypeFor[Config]())
+	for len(b.pending) > 0 {
+		t := b.pending[0]
+		b.pending = b.pending[1:]
+		b.defs[t.Name()] = b.object(t)
+	}
+	root.Schema = "https://json-schema.org/draft/2020-12/schema"
+	root.Title = "mani config"
+	root.Defs = b.defs
+
+	// Templates to extend, parsed from the project node
+	b.defs["Project"].Properties["extends"] = anyOfSchema(
+		stringSchema(),
+		&Schema{Type: "array", Items: stringSchema()},
+	)
+
+	return root
+}
+
+// ref returns a reference to the definition of the struct, which is added once
+// all fields are resolved
+func (b *schemaBuilder) ref(t reflect.Type) *Schema {
+	if _, ok := b.defs[t.Name()]; !ok {
+		b.defs[t.Name()] = nil
+		b.pending = append(b.pending, t)
+	}
+
+	return &Schema{Ref: "#/$defs/" + t.Name()}
+}
+
+func (b *schemaBuilder) object(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}, AdditionalProperties: false}
+	for i := range t.NumField() {
+		field := t.Field(i)
+		key, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if key == "" || key == "-" {
+			continue
+		}
+
+		property, ok := b.fields[t.Name()+"."+key]
+		if !ok {
+			property = b.field(field.Type)
+		}
+		schema.Properties[key] = nullable(property)
+	}
+
+	return schema
+}
+
+func (b *schemaBuilder) field(t reflect.Type) *Schema {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return b.field(t.Elem())
+	case reflect.String:
+		return stringSchema()
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Minimum: core.Ptr(0)}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Schema{Type: "integer"}
+	case reflect.Slice:
+		return &Schema{Type: "array", Items: b.field(t.Elem())}
+	case reflect.Struct:
+		return b.ref(t)
+	default:
+		return &Schema{}
+	}
+}
+
+// nullable allows the key to be declared without a value, `attr:`, which is
+// the same as omitting it
+func nullable(schema *Schema) *Schema {
+	nullSchema := *schema
+	switch {
+	case schema.AnyOf != nil:
+		nullSchema.AnyOf = append(slices.Clone(schema.AnyOf), &Schema{Type: "null"})
+	case schema.Ref != "" || schema.Enum != nil:
+		return anyOfSchema(schema, &Schema{Type: "null"})
+	default:
+		nullSchema.Type = append(slices.Clone(schemaTypes(schema.Type)), "null")
+	}
+
+	return &nullSchema
+}
+
+func schemaTypes(typ any) []string {
+	switch t := typ.(type) {
+	case string:
+		return []string{t}
+	case []string:
+		return t
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,235 @@
+package dao
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestSchema_Fixtures(t *testing.T) {
+	schema := GenerateSchema()
+	if _, err := json.Marshal(schema); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	files, err := filepath.Glob("../../test/fixtures/*/mani.yaml")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("expected fixtures, got %v", err)
+	}
+
+	for _, file := range files {
+		dat, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var node yaml.Node
+		err = yaml.Unmarshal(dat, &node)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, err := range schema.Validate(&node) {
+			t.Errorf("%s: %v", file, err)
+		}
+	}
+}
+
+func TestSchema_Invalid(t *testing.T) {
+	var node yaml.Node
+	err := yaml.Unmarshal([]byte(`
+projects:
+  api:
+    sync: yes please
+    unknown: 1
+specs:
+  default:
+    output: csv
+themes:
+  default:
+    table:
+      header:
+        fg: notacolor
+tasks:
+  build:
+    spec: default
+    cmd: make
+`), &node)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var errs []string
+	for _, err := range GenerateSchema().Validate(&node) {
+		errs = append(errs, err.Error())
+	}
+
+	expected := []string{
+		"invalid `projects.api.sync` on line 4, expected boolean or null, found string",
+		"invalid `projects.api.unknown` on line 5, unknown key",
+		"invalid `specs.default.output` on line 8, expected one of stream, table, html, markdown",
+		"invalid `themes.default.table.header.fg` on line 13, value doesn't match any of the allowed types",
+	}
+	if strings.Join(errs, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(errs, "\n"))
+	}
+}
+
+// Helper functions
+
+type schemaError struct {
+	Path   string
+	Line   int
+	Reason string
+}
+
+func (c *schemaError) Error() string {
+	path := c.Path
+	if path == "" {
+		path = "config"
+	}
+	return fmt.Sprintf("invalid `%s` on line %d, %s", path, c.Line, c.Reason)
+}
+
+// Validate validates the YAML node against the schema and returns an error for
+// each value not matching it. Only the keywords used by GenerateSchema are
+// supported, it's used to check that the schema accepts the fixture configs.
+func (s *Schema) Validate(node *yaml.Node) []error {
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+
+	// Empty config
+	if node.Kind == 0 || node.Kind == yaml.DocumentNode || nodeType(node) == "null" {
+		return nil
+	}
+
+	return s.validate(s, node, "")
+}
+
+func (s *Schema) validate(root *Schema, node *yaml.Node, path string) []error {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+
+	if s.Ref != "" {
+		return root.Defs[strings.TrimPrefix(s.Ref, "#/$defs/")].validate(root, node, path)
+	}
+
+	if s.AnyOf != nil {
+		var matching []*Schema
+		for _, schema := range s.AnyOf {
+			errs := schema.validate(root, node, path)
+			if len(errs) == 0 {
+				return nil
+			}
+			if schema.matchesType(root, node) {
+				matching = append(matching, schema)
+			}
+		}
+
+		// Report the errors of the only schema of the same type as the value
+		if len(matching) == 1 {
+			return matching[0].validate(root, node, path)
+		}
+		return []error{&schemaError{Path: path, Line: node.Line, Reason: "value doesn't match any of the allowed types"}}
+	}
+
+	if !s.matchesType(root, node) {
+		reason := fmt.Sprintf("expected %s, found %s", strings.Join(schemaTypes(s.Type), " or "), nodeType(node))
+		return []error{&schemaError{Path: path, Line: node.Line, Reason: reason}}
+	}
+
+	var errs []error
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if s.Enum != nil && nodeType(node) == "string" && !slices.Contains(s.Enum, node.Value) {
+			reason := fmt.Sprintf("expected one of %s", strings.Join(s.Enum, ", "))
+			errs = append(errs, &schemaError{Path: path, Line: node.Line, Reason: reason})
+		}
+		if s.Pattern != "" && nodeType(node) == "string" && !regexp.MustCompile(s.Pattern).MatchString(node.Value) {
+			reason := fmt.Sprintf("expected value matching %s", s.Pattern)
+			errs = append(errs, &schemaError{Path: path, Line: node.Line, Reason: reason})
+		}
+		if s.Minimum != nil && nodeType(node) == "integer" && strings.HasPrefix(node.Value, "-") {
+			reason := fmt.Sprintf("expected a value of at least %d", *s.Minimum)
+			errs = append(errs, &schemaError{Path: path, Line: node.Line, Reason: reason})
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			if s.Items != nil {
+				errs = append(errs, s.Items.validate(root, item, fmt.Sprintf("%s[%d]", path, i))...)
+			}
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			keyPath := strings.TrimPrefix(path+"."+key, ".")
+			if key == "<<" {
+				continue
+			}
+
+			if property, ok := s.Properties[key]; ok {
+				errs = append(errs, property.validate(root, node.Content[i+1], keyPath)...)
+			} else if additional, ok := s.AdditionalProperties.(*Schema); ok {
+				errs = append(errs, additional.validate(root, node.Content[i+1], keyPath)...)
+			} else if s.AdditionalProperties == false {
+				errs = append(errs, &schemaError{Path: keyPath, Line: node.Content[i].Line, Reason: "unknown key"})
+			}
+		}
+		for _, key := range s.Required {
+			if MappingValue(node, key) == nil {
+				reason := fmt.Sprintf("missing key `%s`", key)
+				errs = append(errs, &schemaError{Path: path, Line: node.Line, Reason: reason})
+			}
+		}
+	}
+
+	return errs
+}
+
+func (s *Schema) matchesType(root *Schema, node *yaml.Node) bool {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if s.Ref != "" {
+		return root.Defs[strings.TrimPrefix(s.Ref, "#/$defs/")].matchesType(root, node)
+	}
+	if s.AnyOf != nil {
+		return slices.ContainsFunc(s.AnyOf, func(schema *Schema) bool { return schema.matchesType(root, node) })
+	}
+
+	types := schemaTypes(s.Type)
+	typ := nodeType(node)
+	return len(types) == 0 || slices.Contains(types, typ) || (typ == "integer" && slices.Contains(types, "number"))
+}
+
+// nodeType returns the JSON Schema type of the YAML node
+func nodeType(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "object"
+	case yaml.SequenceNode:
+		return "array"
+	}
+
+	switch node.ShortTag() {
+	case "!!bool":
+		return "boolean"
+	case "!!int":
+		return "integer"
+	case "!!float":
+		return "number"
+	case "!!null":
+		return "null"
+	default:
+		return "string"
+	}
+}
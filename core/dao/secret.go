@@ -0,0 +1,237 @@
+package dao
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/alajmo/mani/core"
+)
+
+const REDACTED = "***"
+
+// Secret is an env variable whose value is read from a provider when a task
+// runs. Secrets are only passed to the commands mani runs, and their values are
+// replaced with *** in output.
+type Secret struct {
+	Name    string         `yaml:"-"`
+	Command string         `yaml:"command"` // shell command printing the value, `pass show github/token`
+	File    string         `yaml:"file"`    // file containing the value, relative to the config file
+	Env     string         `yaml:"env"`     // env variable in the environment mani runs in
+	Keyring *KeyringSecret `yaml:"keyring"` // entry in the OS keyring
+
+	context     string
+	contextLine int
+}
+
+// KeyringSecret is looked up with `security` on macOS and `secret-tool` on
+// Linux
+type KeyringSecret struct {
+	Service string `yaml:"service"`
+	User    string `yaml:"user"`
+}
+
+func (s *Secret) GetContext() string {
+	return s.context
+}
+
+func (s *Secret) GetContextLine() int {
+	return s.contextLine
+}
+
+// Provider returns the name of the provider of the secret
+func (s Secret) Provider() string {
+	switch {
+	case s.Command != "":
+		return "command"
+	case s.File != "":
+		return "file"
+	case s.Env != "":
+		return "env"
+	case s.Keyring != nil:
+		return "keyring"
+	default:
+		return ""
+	}
+}
+
+func (s Secret) validate() error {
+	providers := 0
+	for _, set := range []bool{s.Command != "", s.File != "", s.Env != "", s.Keyring != nil} {
+		if set {
+			providers++
+		}
+	}
+
+	if providers != 1 {
+		return &core.SecretProviderInvalid{Name: s.Name}
+	}
+
+	return nil
+}
+
+// Resolved secret values by provider and source, secrets are resolved at most
+// once per run
+var (
+	secretCache   = map[string]string{}
+	secretCacheMu sync.Mutex
+)
+
+func (s Secret) cacheKey() string {
+	switch s.Provider() {
+	case "command":
+		return "command:" + s.Command
+	case "file":
+		return "file:" + s.File
+	case "env":
+		return "env:" + s.Env
+	default:
+		return "keyring:" + s.Keyring.Service + ":" + s.Keyring.User
+	}
+}
+
+// Resolve returns the value of the secret, without trailing newline
+func (s Secret) Resolve() (string, error) {
+	key := s.cacheKey()
+
+	secretCacheMu.Lock()
+	defer secretCacheMu.Unlock()
+
+	if value, ok := secretCache[key]; ok {
+		return value, nil
+	}
+
+	var value string
+	switch s.Provider() {
+	case "command":
+		out, err := exec.Command("sh", "-c", s.Command).Output()
+		if err != nil {
+			return "", &core.SecretFailed{Name: s.Name, Err: err}
+		}
+		value = string(out)
+	case "file":
+		dat, err := os.ReadFile(s.File)
+		if err != nil {
+			return "", &core.SecretFailed{Name: s.Name, Err: err}
+		}
+		value = string(dat)
+	case "env":
+		val, ok := os.LookupEnv(s.Env)
+		if !ok {
+			return "", &core.SecretFailed{Name: s.Name, Err: &core.EnvNotSet{Name: s.Env}}
+		}
+		value = val
+	case "keyring":
+		var cmd *exec.Cmd
+		if runtime.GOOS == "darwin" {
+			cmd = exec.Command("security", "find-generic-password", "-s", s.Keyring.Service, "-a", s.Keyring.User, "-w")
+		} else {
+			cmd = exec.Command("secret-tool", "lookup", "service", s.Keyring.Service, "user", s.Keyring.User)
+		}
+		out, err := cmd.Output()
+		if err != nil {
+			return "", &core.SecretFailed{Name: s.Name, Err: err}
+		}
+		value = string(out)
+	}
+
+	value = strings.TrimSuffix(strings.TrimSuffix(value, "\n"), "\r")
+	secretCache[key] = value
+
+	return value, nil
+}
+
+func (c *Config) GetSecretList() ([]Secret, []ResourceErrors[Secret]) {
+	var secrets []Secret
+	count := len(c.Secrets.Content)
+
+	secretErrors := []ResourceErrors[Secret]{}
+	foundErrors := false
+	for i := 0; i < count; i += 2 {
+		secret := &Secret{
+			Name:        c.Secrets.Content[i].Value,
+			context:     c.Path,
+			contextLine: c.Secrets.Content[i].Line,
+		}
+
+		err := c.Secrets.Content[i+1].Decode(secret)
+		if err == nil {
+			err = secret.validate()
+		}
+		if err == nil && secret.File != "" {
+			secret.File, err = core.GetAbsolutePath(c.Dir, secret.File, "")
+		}
+		if err != nil {
+			foundErrors = true
+			secretErrors = append(secretErrors, ResourceErrors[Secret]{Resource: secret, Errors: overlayErrors(err)})
+			continue
+		}
+
+		secrets = append(secrets, *secret)
+	}
+
+	if foundErrors {
+		return secrets, secretErrors
+	}
+
+	return secrets, nil
+}
+
+// ResolveSecrets returns the secrets with the given names in the form
+// [key=value], the other secrets are not resolved
+func (c Config) ResolveSecrets(names []string) ([]string, error) {
+	var envs []string
+	for _, secret := range c.SecretList {
+		if !slices.Contains(names, secret.Name) {
+			continue
+		}
+
+		value, err := secret.Resolve()
+		if err != nil {
+			return nil, err
+		}
+		envs = append(envs, secret.Name+"="+value)
+	}
+
+	return envs, nil
+}
+
+// Redact replaces the values of resolved secrets in s with ***
+func Redact(s string) string {
+	secretCacheMu.Lock()
+	defer secretCacheMu.Unlock()
+
+	for _, value := range secretCache {
+		if value != "" {
+			s = strings.ReplaceAll(s, value, REDACTED)
+		}
+	}
+
+	return s
+}
+
+// secretsNode returns the secrets with their providers, values are never included
+func secretsNode(secrets []Secret) (*yaml.Node, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for _, secret := range secrets {
+		value := &yaml.Node{}
+		err := value.Encode(struct {
+			Command string         `yaml:"command,omitempty"`
+			File    string         `yaml:"file,omitempty"`
+			Env     string         `yaml:"env,omitempty"`
+			Keyring *KeyringSecret `yaml:"keyring,omitempty"`
+			Value   string         `yaml:"value"`
+		}{secret.Command, secret.File, secret.Env, secret.Keyring, REDACTED})
+		if err != nil {
+			return nil, err
+		}
+		node.Content = append(node.Content, ScalarNode(secret.Name), value)
+	}
+
+	return node, nil
+}
@@ -0,0 +1,159 @@
+package dao
+
+import (
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/alajmo/mani/core"
+)
+
+func TestSecret_Resolve(t *testing.T) {
+	t.Setenv("MANI_TEST_SECRET", "from-env")
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"mani.yaml": `
+secrets:
+  COMMAND:
+    command: echo from-command
+  FILE:
+    file: token.txt
+  ENV:
+    env: MANI_TEST_SECRET
+`,
+		"token.txt": "from-file\n",
+	})
+
+	config, err := ReadConfig(filepath.Join(dir, "mani.yaml"), "", "", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Secrets are not part of the env shown in describe
+	if len(config.EnvList) != 0 {
+		t.Errorf("expected no config env, got %v", config.EnvList)
+	}
+
+	envs, err := config.ResolveSecrets([]string{"COMMAND", "FILE", "ENV"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "COMMAND=from-command FILE=from-file ENV=from-env"
+	if strings.Join(envs, " ") != expected {
+		t.Errorf("expected %s, got %v", expected, envs)
+	}
+
+	if out := Redact("token from-file and from-env"); out != "token *** and ***" {
+		t.Errorf("expected secrets to be redacted, got %s", out)
+	}
+
+}
+
+func TestSecret_Redactor(t *testing.T) {
+	secrets := []string{"token", "token-2", ""}
+
+	// Secrets split across reads
+	redactor := core.NewRedactor(iotest.OneByteReader(strings.NewReader("a token-2\nb token tok")), secrets, REDACTED)
+	out, err := io.ReadAll(redactor)
+	if err != nil || string(out) != "a ***\nb *** tok" {
+		t.Errorf("expected redacted output, got %q, %v", out, err)
+	}
+
+	// Partial lines are passed on before the line ends, except for the start
+	// of a secret
+	r, w := io.Pipe()
+	redactor = core.NewRedactor(r, secrets, REDACTED)
+	go func() { _, _ = w.Write([]byte("progress 10% to")) }()
+
+	buf := make([]byte, 64)
+	n, err := redactor.Read(buf)
+	if err != nil || string(buf[:n]) != "progress 10% " {
+		t.Errorf("expected partial line, got %q, %v", buf[:n], err)
+	}
+
+	go func() {
+		_, _ = w.Write([]byte("ken done"))
+		_ = w.Close()
+	}()
+	out, err = io.ReadAll(redactor)
+	if err != nil || string(out) != "*** done" {
+		t.Errorf("expected redacted output, got %q, %v", out, err)
+	}
+}
+
+func TestSecret_TaskSecrets(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"mani.yaml": `
+secrets:
+  GITHUB_TOKEN:
+    env: MANI_TEST_GITHUB_TOKEN
+  NPM_TOKEN:
+    env: MANI_TEST_NPM_TOKEN
+  AWS_KEY:
+    env: MANI_TEST_AWS_KEY
+  FAILING:
+    command: exit 1
+
+tasks:
+  publish:
+    secrets: [AWS_KEY]
+    env:
+      AUTH: token ${NPM_TOKEN}
+    cmd: gh release create --token $GITHUB_TOKEN
+
+  release:
+    commands:
+      - task: publish
+      - cmd: echo $GITHUB_TOKENS
+`,
+	})
+
+	config, err := ReadConfig(filepath.Join(dir, "mani.yaml"), "", "", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Declared and referenced secrets, FAILING is never resolved
+	task, _ := config.GetTask("publish")
+	names := task.SecretNames(config.SecretList)
+	if strings.Join(names, " ") != "GITHUB_TOKEN NPM_TOKEN AWS_KEY" {
+		t.Errorf("unexpected secrets %v", names)
+	}
+	t.Setenv("MANI_TEST_GITHUB_TOKEN", "a")
+	t.Setenv("MANI_TEST_NPM_TOKEN", "b")
+	t.Setenv("MANI_TEST_AWS_KEY", "c")
+	if _, err := config.ResolveSecrets(names); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// Secrets of referenced tasks, $GITHUB_TOKENS is another variable
+	task, _ = config.GetTask("release")
+	names = task.SecretNames(config.SecretList)
+	if strings.Join(names, " ") != "GITHUB_TOKEN AWS_KEY" {
+		t.Errorf("unexpected secrets %v", names)
+	}
+
+	writeTestFiles(t, dir, map[string]string{
+		"mani.yaml": "tasks:\n  build:\n    secrets: [MISSING]\n    cmd: make\n",
+	})
+	_, err = ReadConfig(filepath.Join(dir, "mani.yaml"), "", "", false)
+	if err == nil || !strings.Contains(err.Error(), "cannot find secrets `MISSING`") {
+		t.Errorf("expected missing secret error, got %v", err)
+	}
+}
+
+func TestSecret_InvalidProvider(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"mani.yaml": "secrets:\n  TOKEN:\n    command: echo a\n    env: B\n",
+	})
+
+	_, err := ReadConfig(filepath.Join(dir, "mani.yaml"), "", "", false)
+	if err == nil || !strings.Contains(err.Error(), "secret `TOKEN` must set exactly one of") {
+		t.Errorf("expected invalid provider error, got %v", err)
+	}
+}
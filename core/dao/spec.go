@@ -51,14 +51,10 @@ func (c *Config) GetSpecList() ([]Spec, []ResourceErrors[Spec]) {
 			continue
 		}
 
-		switch spec.Output {
-		case "", "table", "stream", "html", "markdown":
-		default:
+		err = spec.validate()
+		if err != nil {
 			foundErrors = true
-			specError := ResourceErrors[Spec]{
-				Resource: spec,
-				Errors:   []error{&core.SpecOutputError{Name: spec.Name, Output: spec.Output}},
-			}
+			specError := ResourceErrors[Spec]{Resource: spec, Errors: []error{err}}
 			specErrors = append(specErrors, specError)
 		}
 
@@ -76,6 +72,15 @@ func (c *Config) GetSpecList() ([]Spec, []ResourceErrors[Spec]) {
 	return specs, nil
 }
 
+func (s Spec) validate() error {
+	switch s.Output {
+	case "", "table", "stream", "html", "markdown":
+		return nil
+	default:
+		return &core.SpecOutputError{Name: s.Name, Output: s.Output}
+	}
+}
+
 func (c Config) GetSpec(name string) (*Spec, error) {
 	for _, spec := range c.SpecList {
 		if name == spec.Name {
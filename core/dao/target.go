@@ -49,16 +49,11 @@ func (c *Config) GetTargetList() ([]Target, []ResourceErrors[Target]) {
 			continue
 		}
 
-		if target.TagsExpr != "" {
-			valid := validateExpression(target.TagsExpr)
-			if valid != nil {
-				foundErrors = true
-				targetError := ResourceErrors[Target]{
-					Resource: target,
-					Errors:   []error{&core.TargetTagsExprError{Name: target.Name, Err: valid}},
-				}
-				targetErrors = append(targetErrors, targetError)
-			}
+		err = target.validate()
+		if err != nil {
+			foundErrors = true
+			targetError := ResourceErrors[Target]{Resource: target, Errors: []error{err}}
+			targetErrors = append(targetErrors, targetError)
 		}
 
 		targets = append(targets, *target)
@@ -71,6 +66,17 @@ func (c *Config) GetTargetList() ([]Target, []ResourceErrors[Target]) {
 	return targets, nil
 }
 
+func (t Target) validate() error {
+	if t.TagsExpr != "" {
+		valid := validateExpression(t.TagsExpr)
+		if valid != nil {
+			return &core.TargetTagsExprError{Name: t.Name, Err: valid}
+		}
+	}
+
+	return nil
+}
+
 func (c Config) GetTarget(name string) (*Target, error) {
 	for _, target := range c.TargetList {
 		if name == target.Name {
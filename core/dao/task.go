@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"regexp"
+	"slices"
 	"strings"
 	"time"
 
@@ -28,6 +30,7 @@ type Command struct {
 	TTY     bool      `yaml:"tty"`
 	Env     yaml.Node `yaml:"env"`
 	EnvList []string  `yaml:"-"`
+	Secrets []string  `yaml:"-"` // secrets declared by the referenced task
 
 	// Internal
 	ShellProgram string   `yaml:"-"` // should be in the format: <program>, example: "sh", "node"
@@ -41,20 +44,29 @@ type Task struct {
 
 	Name     string    `yaml:"name"`
 	Desc     string    `yaml:"desc"`
+	Extends  string    `yaml:"extends"`
 	Shell    string    `yaml:"shell"`
 	Cmd      string    `yaml:"cmd"`
 	Commands []Command `yaml:"commands"`
 	EnvList  []string  `yaml:"-"`
 	TTY      bool      `yaml:"tty"`
+	Secrets  []string  `yaml:"secrets"` // secrets passed to the task, in addition to the ones referenced in its commands and env
 
-	Env    yaml.Node `yaml:"env"`
-	Spec   yaml.Node `yaml:"spec"`
-	Target yaml.Node `yaml:"target"`
-	Theme  yaml.Node `yaml:"theme"`
+	EnvFileList []EnvFile `yaml:"-"`
+
+	Env      yaml.Node `yaml:"env"`
+	EnvFiles yaml.Node `yaml:"env_files"`
+	Spec     yaml.Node `yaml:"spec"`
+	Target   yaml.Node `yaml:"target"`
+	Theme    yaml.Node `yaml:"theme"`
 
 	// Internal
-	ShellProgram string   `yaml:"-"` // should be in the format: <program>, example: "sh", "node"
-	CmdArg       []string `yaml:"-"` // is in the format ["-c echo hello world"] or ["-c", "echo hello world"], it includes the shell flag
+	ShellProgram string       `yaml:"-"` // should be in the format: <program>, example: "sh", "node"
+	CmdArg       []string     `yaml:"-"` // is in the format ["-c echo hello world"] or ["-c", "echo hello world"], it includes the shell flag
+	Namespace    string       `yaml:"-"` // namespace of the import the task is declared in, the name is prefixed with it
+	FromProjects bool         `yaml:"-"` // task is only declared in project tasks files
+	Sources      []TaskSource `yaml:"-"` // projects declaring their own implementation of the task
+	declared     []string     // keys set in the task definition, the remaining fields are inherited with extends
 	context      string
 	contextLine  int
 }
@@ -80,10 +92,20 @@ func (t *Task) ParseTask(config Config, taskErrors *ResourceErrors[Task]) {
 	t.ShellProgram = program
 	t.CmdArg = cmdArgs
 
+	var missingSecrets []string
+	for _, name := range t.Secrets {
+		if !slices.ContainsFunc(config.SecretList, func(s Secret) bool { return s.Name == name }) {
+			missingSecrets = append(missingSecrets, name)
+		}
+	}
+	if len(missingSecrets) > 0 {
+		taskErrors.Errors = append(taskErrors.Errors, &core.SecretNotFound{Name: missingSecrets})
+	}
+
 	for j, cmd := range t.Commands {
 		// Task reference
 		if cmd.Task != "" {
-			cmdRef, err := config.GetCommand(cmd.Task)
+			cmdRef, err := config.getCommand(cmd.Task, t.Namespace)
 			if err != nil {
 				taskErrors.Errors = append(taskErrors.Errors, err)
 				continue
@@ -185,6 +207,132 @@ func (t *Task) ParseTask(config Config, taskErrors *ResourceErrors[Task]) {
 	}
 }
 
+// extendTasks resolves the extends of all tasks, before the tasks and the
+// commands referencing them are parsed
+func (c *Config) extendTasks() []ResourceErrors[Task] {
+	var taskErrors []ResourceErrors[Task]
+
+	resolved := make([]bool, len(c.TaskList))
+	for i := range c.TaskList {
+		err := c.extendTask(i, resolved, []string{})
+		if err != nil {
+			taskErrors = append(taskErrors, ResourceErrors[Task]{Resource: &c.TaskList[i], Errors: []error{err}})
+		}
+	}
+
+	return taskErrors
+}
+
+// extendTask resolves the extends of the task, and of the tasks it extends
+// first. chain holds the tasks being resolved to detect cycles.
+func (c *Config) extendTask(i int, resolved []bool, chain []string) error {
+	task := &c.TaskList[i]
+	if resolved[i] || task.Extends == "" {
+		resolved[i] = true
+		return nil
+	}
+	chain = append(chain, task.Name)
+
+	j, err := c.findTask(task.Extends, task.Namespace)
+	if err != nil {
+		return err
+	}
+	if j < 0 {
+		return &core.TaskNotFound{Name: []string{task.Extends}}
+	}
+
+	if slices.Contains(chain, c.TaskList[j].Name) {
+		return &core.TaskExtendsCycle{Names: append(chain, c.TaskList[j].Name)}
+	}
+
+	err = c.extendTask(j, resolved, chain)
+	if err != nil {
+		return err
+	}
+
+	task.inherit(c.TaskList[j])
+	resolved[i] = true
+
+	return nil
+}
+
+// inherit sets the fields not declared in the task from the task it extends.
+// Env is merged, with the task's own values taking precedence. Cmd and
+// commands are inherited together, and only if the task declares neither of
+// them.
+func (t *Task) inherit(parent Task) {
+	declared := func(key string) bool {
+		return slices.Contains(t.declared, key)
+	}
+
+	if !declared("desc") {
+		t.Desc = parent.Desc
+	}
+	if !declared("shell") {
+		t.Shell = parent.Shell
+	}
+	if !declared("cmd") && !declared("commands") {
+		t.Cmd = parent.Cmd
+		t.Commands = slices.Clone(parent.Commands)
+	}
+	if !declared("tty") {
+		t.TTY = parent.TTY
+	}
+	if !declared("secrets") {
+		t.Secrets = parent.Secrets
+	}
+
+	if !declared("env_files") {
+		t.EnvFiles = parent.EnvFiles
+		t.EnvFileList = parent.EnvFileList
+	}
+
+	if !declared("env") {
+		t.Env = parent.Env
+	} else if parent.Env.Kind != 0 {
+		t.Env = *mergeMappingNodes(&parent.Env, &t.Env)
+	}
+
+	if !declared("spec") {
+		t.Spec = parent.Spec
+	}
+	if !declared("target") {
+		t.Target = parent.Target
+	}
+	if !declared("theme") {
+		t.Theme = parent.Theme
+	}
+}
+
+// declaredKeys returns the keys set in a task definition, including the keys
+// of merged mappings. The shorthand definition only sets cmd.
+func declaredKeys(node *yaml.Node) []string {
+	if node.Kind == yaml.AliasNode {
+		return declaredKeys(node.Alias)
+	}
+	if node.Kind == yaml.ScalarNode {
+		return []string{"cmd"}
+	}
+
+	keys := []string{}
+	if node.Kind == yaml.SequenceNode {
+		for _, n := range node.Content {
+			keys = append(keys, declaredKeys(n)...)
+		}
+		return keys
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == "<<" {
+			keys = append(keys, declaredKeys(node.Content[i+1])...)
+		} else {
+			keys = append(keys, node.Content[i].Value)
+		}
+	}
+
+	return keys
+}
+
 func TaskSpinner() (yacspin.Spinner, error) {
 	var cfg yacspin.Config
 
@@ -239,6 +387,7 @@ func (c *Config) GetTaskList() ([]Task, []ResourceErrors[Task]) {
 			Name:        c.Tasks.Content[i].Value,
 			context:     c.Path,
 			contextLine: c.Tasks.Content[i].Line,
+			declared:    declaredKeys(c.Tasks.Content[i+1]),
 		}
 
 		// Shorthand definition: example_task: echo 123
@@ -254,6 +403,28 @@ func (c *Config) GetTaskList() ([]Task, []ResourceErrors[Task]) {
 			}
 		}
 
+		// Cache durations of the env are validated here, the env is evaluated
+		// when the task runs
+		_, errs := ParseEnvCache(task.Env)
+		for _, cmd := range task.Commands {
+			_, cmdErrs := ParseEnvCache(cmd.Env)
+			errs = append(errs, cmdErrs...)
+		}
+		if len(errs) > 0 {
+			foundErrors = true
+			taskErrors = append(taskErrors, ResourceErrors[Task]{Resource: task, Errors: errs})
+			continue
+		}
+
+		// Env files of tasks are relative to the config file
+		envFiles, errs := ParseEnvFiles(task.EnvFiles, c.Dir)
+		if len(errs) > 0 {
+			foundErrors = true
+			taskErrors = append(taskErrors, ResourceErrors[Task]{Resource: task, Errors: errs})
+			continue
+		}
+		task.EnvFileList = envFiles
+
 		tasks = append(tasks, *task)
 	}
 
@@ -270,12 +441,14 @@ func ParseTaskEnv(
 	parentEnv []string,
 	configEnv []string,
 ) ([]string, error) {
-	cmdEnv, err := EvaluateEnv(ParseNodeEnv(env))
+	// Cache durations are validated when the config is read
+	envCache, _ := ParseEnvCache(env)
+	cmdEnv, err := EvaluateEnv(ParseNodeEnv(env), envCache)
 	if err != nil {
 		return []string{}, err
 	}
 
-	pEnv, err := EvaluateEnv(parentEnv)
+	pEnv, err := EvaluateEnv(parentEnv, nil)
 	if err != nil {
 		return []string{}, err
 	}
@@ -287,7 +460,7 @@ func ParseTaskEnv(
 
 func ParseTasksEnv(tasks []Task) {
 	for i := range tasks {
-		envs, err := ParseTaskEnv(tasks[i].Env, []string{}, []string{}, []string{})
+		envs, err := ParseTaskEnv(tasks[i].Env, []string{}, []string{}, EnvFilesEnv(tasks[i].EnvFileList))
 		core.CheckIfError(err)
 
 		tasks[i].EnvList = envs
@@ -401,11 +574,13 @@ func (c Config) GetTasksByNames(names []string) ([]Task, error) {
 			continue
 		}
 
-		for _, task := range c.TaskList {
-			if name == task.Name {
-				foundTasks[task.Name] = true
-				filteredTasks = append(filteredTasks, task)
-			}
+		i, err := c.findTask(name, "")
+		if err != nil {
+			return []Task{}, err
+		}
+		if i >= 0 {
+			foundTasks[name] = true
+			filteredTasks = append(filteredTasks, c.TaskList[i])
 		}
 	}
 
@@ -441,32 +616,71 @@ func (c Config) GetTaskNameAndDesc() []string {
 	return taskNames
 }
 
+// GetTask returns the task with the given name, namespaced tasks can also be
+// referenced without namespace if the name is unambiguous
 func (c Config) GetTask(name string) (*Task, error) {
-	for _, cmd := range c.TaskList {
-		if name == cmd.Name {
-			return &cmd, nil
-		}
+	i, err := c.findTask(name, "")
+	if err != nil {
+		return nil, err
+	}
+	if i < 0 {
+		return nil, &core.TaskNotFound{Name: []string{name}}
 	}
 
-	return nil, &core.TaskNotFound{Name: []string{name}}
+	task := c.TaskList[i]
+	return &task, nil
 }
 
 func (c Config) GetCommand(taskName string) (*Command, error) {
-	for _, cmd := range c.TaskList {
-		if taskName == cmd.Name {
-			cmdRef := &Command{
-				Name:    cmd.Name,
-				Desc:    cmd.Desc,
-				EnvList: cmd.EnvList,
-				Shell:   cmd.Shell,
-				Cmd:     cmd.Cmd,
-			}
+	return c.getCommand(taskName, "")
+}
+
+// getCommand returns the task as a command, references from a namespaced
+// task prefer tasks in the same namespace
+func (c Config) getCommand(taskName string, scope string) (*Command, error) {
+	i, err := c.findTask(taskName, scope)
+	if err != nil {
+		return nil, err
+	}
+	if i < 0 {
+		return nil, &core.TaskNotFound{Name: []string{taskName}}
+	}
+
+	cmd := c.TaskList[i]
+	cmdRef := &Command{
+		Name:    cmd.Name,
+		Desc:    cmd.Desc,
+		EnvList: cmd.EnvList,
+		Shell:   cmd.Shell,
+		Cmd:     cmd.Cmd,
+		Secrets: cmd.Secrets,
+	}
+
+	return cmdRef, nil
+}
 
-			return cmdRef, nil
+// SecretNames returns the names of the secrets the task uses: the secrets
+// declared by the task and the tasks its commands reference, and the secrets
+// referenced as $NAME or ${NAME} in its commands and env
+func (t Task) SecretNames(secrets []Secret) []string {
+	sources := []string{t.Cmd}
+	sources = append(sources, ParseNodeEnv(t.Env)...)
+	declared := slices.Clone(t.Secrets)
+	for _, cmd := range t.Commands {
+		sources = append(sources, cmd.Cmd)
+		sources = append(sources, ParseNodeEnv(cmd.Env)...)
+		declared = append(declared, cmd.Secrets...)
+	}
+
+	var names []string
+	for _, secret := range secrets {
+		ref := regexp.MustCompile(`\$(\{` + regexp.QuoteMeta(secret.Name) + `\}|` + regexp.QuoteMeta(secret.Name) + `\b)`)
+		if slices.Contains(declared, secret.Name) || slices.ContainsFunc(sources, ref.MatchString) {
+			names = append(names, secret.Name)
 		}
 	}
 
-	return nil, &core.TaskNotFound{Name: []string{taskName}}
+	return names
 }
 
 func (t Task) ConvertTaskToCommand() Command {
@@ -536,10 +750,17 @@ func ParseSingleTask(
 	projects, err := config.GetTaskProjects(task, runFlags, setFlags)
 	core.CheckIfError(err)
 
+	// Tasks declared in project tasks files only run in the projects declaring them
+	if task.FromProjects {
+		projects = slices.DeleteFunc(projects, func(p Project) bool { return !p.HasTask(task.Name) })
+	}
+
+	// Projects can override the task
 	var tasks []Task
-	for range projects {
+	for _, project := range projects {
+		projectTask := project.GetTask(*task)
 		t := Task{}
-		err := copier.Copy(&t, &task)
+		err := copier.Copy(&t, &projectTask)
 		core.CheckIfError(err)
 		tasks = append(tasks, t)
 	}
@@ -561,25 +782,43 @@ func ParseManyTasks(
 	taskErrors := make([]ResourceErrors[Task], 1)
 	parentTask.ParseTask(*config, &taskErrors[0])
 
+	var namedTasks []Task
 	for _, taskName := range taskNames {
 		task, err := config.GetTask(taskName)
 		core.CheckIfError(err)
-
-		if task.Cmd != "" {
-			cmd := task.ConvertTaskToCommand()
-			parentTask.Commands = append(parentTask.Commands, cmd)
-		} else if len(task.Commands) > 0 {
-			parentTask.Commands = append(parentTask.Commands, task.Commands...)
-		}
+		namedTasks = append(namedTasks, *task)
 	}
 
-	projects, err := config.GetTaskProjects(&parentTask, runFlags, setFlags)
+	targetProjects, err := config.GetTaskProjects(&parentTask, runFlags, setFlags)
 	var tasks []Task
-	for range projects {
+	var projects []Project
+	for _, project := range targetProjects {
 		t := Task{}
 		err := copier.Copy(&t, &parentTask)
 		core.CheckIfError(err)
+
+		// Projects can override any of the tasks, tasks declared in project
+		// tasks files only run in the projects declaring them
+		for _, task := range namedTasks {
+			if task.FromProjects && !project.HasTask(task.Name) {
+				continue
+			}
+			task = project.GetTask(task)
+			t.Secrets = append(t.Secrets, task.Secrets...)
+			if task.Cmd != "" {
+				cmd := task.ConvertTaskToCommand()
+				t.Commands = append(t.Commands, cmd)
+			} else if len(task.Commands) > 0 {
+				t.Commands = append(t.Commands, task.Commands...)
+			}
+		}
+
+		if len(t.Commands) == 0 {
+			continue
+		}
+
 		tasks = append(tasks, t)
+		projects = append(projects, project)
 	}
 
 	if len(projects) == 0 {
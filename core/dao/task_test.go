@@ -1,8 +1,10 @@
 package dao
 
 import (
+	"path/filepath"
 	"reflect"
 	"sort"
+	"strings"
 	"testing"
 
 	"github.com/alajmo/mani/core"
@@ -423,3 +425,99 @@ func TestConfig_FilterProjects(t *testing.T) {
 		})
 	}
 }
+
+func TestTask_Extends(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"mani.yaml": `
+specs:
+  table:
+    output: table
+
+tasks:
+  release:
+    commands:
+      - task: deploy
+
+  base:
+    desc: base task
+    spec: table
+    tty: true
+    env:
+      STAGE: dev
+      REGION: eu
+    cmd: echo $STAGE
+
+  deploy:
+    extends: base
+    env:
+      STAGE: staging
+
+  interactive:
+    extends: base
+    tty: false
+    commands:
+      - cmd: echo step
+
+  deploy-prod:
+    extends: deploy
+    env:
+      STAGE: prod
+    cmd: echo prod
+`,
+	})
+
+	config, err := ReadConfig(filepath.Join(dir, "mani.yaml"), "", "", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tasks, err := config.GetTasksByNames([]string{"deploy", "deploy-prod"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ParseTasksEnv(tasks)
+
+	expected := []struct {
+		cmd string
+		env []string
+	}{
+		{cmd: "echo $STAGE", env: []string{"STAGE=staging", "REGION=eu"}},
+		{cmd: "echo prod", env: []string{"STAGE=prod", "REGION=eu"}},
+	}
+
+	for i, task := range tasks {
+		if task.Cmd != expected[i].cmd || task.Desc != "base task" || task.SpecData.Name != "table" {
+			t.Errorf("%s: expected cmd `%s`, desc and spec to be inherited, got %+v", task.Name, expected[i].cmd, task)
+		}
+		if !reflect.DeepEqual(task.EnvList, expected[i].env) {
+			t.Errorf("%s: expected env %v, got %v", task.Name, expected[i].env, task.EnvList)
+		}
+	}
+
+	// Commands reference the extended task, even if declared before it
+	release, _ := config.GetTask("release")
+	if len(release.Commands) != 1 || release.Commands[0].Cmd != "echo $STAGE" {
+		t.Errorf("expected release to run the extended deploy command, got %+v", release.Commands)
+	}
+
+	// Declared fields override the parent, even if empty, and commands replace cmd
+	interactive, _ := config.GetTask("interactive")
+	if interactive.TTY || interactive.Cmd != "" || len(interactive.Commands) != 1 {
+		t.Errorf("expected tty and cmd not to be inherited, got %+v", interactive)
+	}
+
+	// Missing and circular extends
+	writeTestFiles(t, dir, map[string]string{
+		"mani.yaml": "tasks:\n  a:\n    extends: b\n  b:\n    extends: a\n  c:\n    extends: missing\n",
+	})
+	_, err = ReadConfig(filepath.Join(dir, "mani.yaml"), "", "", false)
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+	for _, msg := range []string{"circular task extends `a` -> `b` -> `a`", "cannot find tasks `missing`"} {
+		if !strings.Contains(err.Error(), msg) {
+			t.Errorf("expected error to contain %q, got:\n%s", msg, err)
+		}
+	}
+}
@@ -0,0 +1,332 @@
+package dao
+
+import (
+	"bytes"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/alajmo/mani/core"
+)
+
+// YAMLFile is a config file loaded as a yaml.Node tree, used when mani modifies
+// config files. Comments, key order, indentation and blank lines between entries
+// are kept when the file is written back.
+type YAMLFile struct {
+	Path string
+	Doc  *yaml.Node
+
+	src    []byte
+	lines  []string
+	spaced map[*yaml.Node]bool // Nodes to precede by a blank line
+}
+
+func ReadYAMLFile(path string) (*YAMLFile, error) {
+	if !isYAMLFile(path) {
+		return nil, &core.ConfigNotYAML{Path: path}
+	}
+
+	dat, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc yaml.Node
+	err = yaml.Unmarshal(dat, &doc)
+	if err != nil {
+		return nil, err
+	}
+
+	// Empty file
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode}
+	}
+
+	return &YAMLFile{Path: path, Doc: &doc, src: dat, lines: strings.Split(string(dat), "\n"), spaced: make(map[*yaml.Node]bool)}, nil
+}
+
+// Root returns the top-level mapping, creating it if the file is empty
+func (f *YAMLFile) Root() *yaml.Node {
+	if len(f.Doc.Content) == 0 {
+		f.Doc.Content = append(f.Doc.Content, &yaml.Node{Kind: yaml.MappingNode})
+	}
+
+	return f.Doc.Content[0]
+}
+
+// Section returns the value of a top-level key, creating an empty mapping if
+// the key is missing or null
+func (f *YAMLFile) Section(key string) *yaml.Node {
+	root := f.Root()
+	node := MappingValue(root, key)
+	if node == nil {
+		node = &yaml.Node{Kind: yaml.MappingNode}
+		SetMappingValue(root, key, node)
+	} else if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		*node = yaml.Node{Kind: yaml.MappingNode, Line: node.Line, Column: node.Column}
+	}
+
+	return node
+}
+
+func (f *YAMLFile) Marshal() ([]byte, error) {
+	return f.marshal(f.indent())
+}
+
+func (f *YAMLFile) marshal(indent int) ([]byte, error) {
+	blanks := make(map[*yaml.Node]bool)
+	f.findBlankLines(f.Doc, blanks)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(indent)
+	err := encoder.Encode(f.Doc)
+	if err != nil {
+		return nil, err
+	}
+	err = encoder.Close()
+	if err != nil {
+		return nil, err
+	}
+	out := buf.Bytes()
+
+	if len(blanks) == 0 {
+		return out, nil
+	}
+
+	// Parse the output once more to find the lines of the nodes that were
+	// preceded by a blank line in the source file.
+	var outDoc yaml.Node
+	err = yaml.Unmarshal(out, &outDoc)
+	if err != nil {
+		return out, nil
+	}
+
+	blankLines := make(map[int]bool)
+	mapBlankLines(f.Doc, &outDoc, blanks, blankLines)
+
+	lines := strings.Split(string(out), "\n")
+	var result []string
+	for i, line := range lines {
+		if blankLines[i+1] && len(result) > 0 && result[len(result)-1] != "" {
+			result = append(result, "")
+		}
+		result = append(result, line)
+	}
+
+	return []byte(strings.Join(result, "\n")), nil
+}
+
+func (f *YAMLFile) Write() error {
+	out, err := f.Marshal()
+	if err != nil {
+		return err
+	}
+
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(f.Path); err == nil {
+		mode = info.Mode()
+	}
+
+	return os.WriteFile(f.Path, out, mode)
+}
+
+// indent returns the indentation used in the source file, defaults to 2
+func (f *YAMLFile) indent() int {
+	for line := range strings.SplitSeq(string(f.src), "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		if trimmed == "" || trimmed == line || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		return len(line) - len(trimmed)
+	}
+
+	return 2
+}
+
+// findBlankLines marks the nodes that are preceded by a blank line in the source.
+// New nodes (without a line number) inherit the spacing of their previous sibling.
+// The first entry of a mapping or sequence is never marked, it may be an entry
+// that was moved up when the entries before it were removed. Nodes in spaced are
+// always marked.
+func (f *YAMLFile) findBlankLines(node *yaml.Node, blanks map[*yaml.Node]bool) {
+	var walk func(n *yaml.Node)
+	walk = func(n *yaml.Node) {
+		step := 1
+		if n.Kind == yaml.MappingNode {
+			step = 2
+		}
+
+		if n.Kind == yaml.MappingNode || n.Kind == yaml.SequenceNode {
+			for i := step; i < len(n.Content); i += step {
+				child := n.Content[i]
+				if f.spaced[child] {
+					blanks[child] = true
+				} else if child.Line > 0 {
+					blanks[child] = f.isBlank(child)
+				} else {
+					blanks[child] = blanks[n.Content[i-step]]
+				}
+			}
+		}
+
+		for _, child := range n.Content {
+			walk(child)
+		}
+	}
+
+	walk(node)
+}
+
+// isBlank returns true if the node is preceded by a blank line in the source
+func (f *YAMLFile) isBlank(n *yaml.Node) bool {
+	line := n.Line - commentLines(n.HeadComment) - 1
+	return line >= 1 && line <= len(f.lines) && strings.TrimSpace(f.lines[line-1]) == ""
+}
+
+func mapBlankLines(src *yaml.Node, out *yaml.Node, blanks map[*yaml.Node]bool, lines map[int]bool) {
+	if blanks[src] {
+		lines[out.Line-commentLines(out.HeadComment)] = true
+	}
+
+	if len(src.Content) != len(out.Content) {
+		return
+	}
+
+	for i := range src.Content {
+		mapBlankLines(src.Content[i], out.Content[i], blanks, lines)
+	}
+}
+
+func commentLines(comment string) int {
+	if comment == "" {
+		return 0
+	}
+
+	return strings.Count(comment, "\n") + 1
+}
+
+// MappingValue returns the value for key in a mapping node, or nil if not found
+func MappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+// SetMappingValue replaces the value of key in a mapping node, or appends the key if missing
+func SetMappingValue(node *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content[i+1] = value
+			return
+		}
+	}
+
+	node.Content = append(node.Content, ScalarNode(key), value)
+}
+
+// RemoveMappingKey removes key from a mapping node and returns true if it was found
+func RemoveMappingKey(node *yaml.Node, key string) bool {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+// EncodeYAML encodes a node with 2 space indentation
+func EncodeYAML(node *yaml.Node) (string, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	err := encoder.Encode(node)
+	if err != nil {
+		return "", err
+	}
+
+	err = encoder.Close()
+	if err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+func ScalarNode(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
+
+// ProjectNode returns the yaml node for a project entry
+func ProjectNode(project Project) *yaml.Node {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+
+	if project.Path != "" && project.Path != project.Name {
+		node.Content = append(node.Content, ScalarNode("path"), ScalarNode(project.Path))
+	}
+
+	if project.Desc != "" {
+		node.Content = append(node.Content, ScalarNode("desc"), ScalarNode(project.Desc))
+	}
+
+	if project.URL != "" {
+		node.Content = append(node.Content, ScalarNode("url"), ScalarNode(project.URL))
+	}
+
+	if project.Branch != "" {
+		node.Content = append(node.Content, ScalarNode("branch"), ScalarNode(project.Branch))
+	}
+
+	if len(project.Tags) > 0 {
+		tags := &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+		for _, tag := range project.Tags {
+			tags.Content = append(tags.Content, ScalarNode(tag))
+		}
+		node.Content = append(node.Content, ScalarNode("tags"), tags)
+	}
+
+	if len(project.RemoteList) > 0 {
+		remotes := &yaml.Node{Kind: yaml.MappingNode}
+		for _, remote := range project.RemoteList {
+			remotes.Content = append(remotes.Content, ScalarNode(remote.Name), ScalarNode(remote.URL))
+		}
+		node.Content = append(node.Content, ScalarNode("remotes"), remotes)
+	}
+
+	if len(project.WorktreeList) > 0 {
+		worktrees := &yaml.Node{Kind: yaml.SequenceNode}
+		for _, wt := range project.WorktreeList {
+			worktrees.Content = append(worktrees.Content, WorktreeNode(wt))
+		}
+		node.Content = append(node.Content, ScalarNode("worktrees"), worktrees)
+	}
+
+	// Project without any fields, `project:`
+	if len(node.Content) == 0 {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null"}
+	}
+
+	return node
+}
+
+func WorktreeNode(wt Worktree) *yaml.Node {
+	return &yaml.Node{
+		Kind: yaml.MappingNode,
+		Content: []*yaml.Node{
+			ScalarNode("path"), ScalarNode(wt.Path),
+			ScalarNode("branch"), ScalarNode(wt.Branch),
+		},
+	}
+}
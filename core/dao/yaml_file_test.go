@@ -0,0 +1,107 @@
+package dao
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestYAMLFile_Marshal(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		modify   func(f *YAMLFile)
+		expected string
+	}{
+		{
+			name: "unmodified file is kept as-is",
+			input: `# Projects
+projects:
+  # project a
+  a:
+    url: https://github.com/a/a
+
+  b:
+    path: frontend/b
+    tags: [frontend]
+
+# Tasks
+tasks:
+  hello: echo hello
+`,
+			modify: func(f *YAMLFile) {},
+			expected: `# Projects
+projects:
+  # project a
+  a:
+    url: https://github.com/a/a
+
+  b:
+    path: frontend/b
+    tags: [frontend]
+
+# Tasks
+tasks:
+  hello: echo hello
+`,
+		},
+		{
+			name: "new project inherits spacing and indentation",
+			input: `projects:
+    a:
+        url: https://github.com/a/a
+
+    b:
+        path: b
+`,
+			modify: func(f *YAMLFile) {
+				SetMappingValue(f.Section("projects"), "c", ProjectNode(Project{Name: "c", Path: "c", URL: "https://github.com/c/c"}))
+			},
+			expected: `projects:
+    a:
+        url: https://github.com/a/a
+
+    b:
+        path: b
+
+    c:
+        url: https://github.com/c/c
+`,
+		},
+		{
+			name:  "section is created in empty file",
+			input: ``,
+			modify: func(f *YAMLFile) {
+				SetMappingValue(f.Section("projects"), "a", ProjectNode(Project{Name: "a", Path: "a"}))
+			},
+			expected: `projects:
+  a:
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "mani.yaml")
+			if err := os.WriteFile(path, []byte(tt.input), 0644); err != nil {
+				t.Fatalf("failed to write file: %v", err)
+			}
+
+			f, err := ReadYAMLFile(path)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			tt.modify(f)
+
+			out, err := f.Marshal()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if string(out) != tt.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tt.expected, out)
+			}
+		})
+	}
+}
@@ -8,6 +8,15 @@ import (
 	"github.com/gookit/color"
 )
 
+type EnvCacheInvalid struct {
+	Name  string
+	Cache string
+}
+
+func (c *EnvCacheInvalid) Error() string {
+	return fmt.Sprintf("invalid cache `%s` for env `%s`, expected a duration such as 30s, 5m or 1h", c.Cache, c.Name)
+}
+
 type ConfigEnvFailed struct {
 	Name string
 	Err  string
@@ -57,6 +66,14 @@ func (p *PathDoesNotExist) Error() string {
 	return fmt.Sprintf("path `%s` does not exist", p.Path)
 }
 
+type PathAlreadyExists struct {
+	Path string
+}
+
+func (p *PathAlreadyExists) Error() string {
+	return fmt.Sprintf("path `%s` already exists", p.Path)
+}
+
 type TagNotFound struct {
 	Tags []string
 }
@@ -99,6 +116,30 @@ func (c *TaskNotFound) Error() string {
 	return fmt.Sprintf("cannot find tasks %s", tasks)
 }
 
+type ProjectTemplateNotFound struct {
+	Name string
+}
+
+func (c *ProjectTemplateNotFound) Error() string {
+	return fmt.Sprintf("cannot find project template `%s`", c.Name)
+}
+
+type ProjectTemplateCycle struct {
+	Names []string
+}
+
+func (c *ProjectTemplateCycle) Error() string {
+	return fmt.Sprintf("found circular project template `%s`", strings.Join(c.Names, "` -> `"))
+}
+
+type TaskExtendsCycle struct {
+	Names []string
+}
+
+func (c *TaskExtendsCycle) Error() string {
+	return fmt.Sprintf("found circular task extends `%s`", strings.Join(c.Names, "` -> `"))
+}
+
 type ThemeNotFound struct {
 	Name string
 }
@@ -132,6 +173,14 @@ func (c *TargetNotFound) Error() string {
 	return fmt.Sprintf("cannot find target `%s`", c.Name)
 }
 
+type ProfileNotFound struct {
+	Name string
+}
+
+func (c *ProfileNotFound) Error() string {
+	return fmt.Sprintf("cannot find profile `%s`", c.Name)
+}
+
 type TargetTagsExprError struct {
 	Name string
 	Err  error
@@ -163,6 +212,24 @@ func (c *WorktreePathRequired) Error() string {
 	return "worktree path is required"
 }
 
+type WorktreeNotFound struct {
+	Project string
+	Path    string
+}
+
+func (c *WorktreeNotFound) Error() string {
+	return fmt.Sprintf("cannot find worktree `%s` in project `%s`", c.Path, c.Project)
+}
+
+type WorktreeAlreadyExists struct {
+	Project string
+	Path    string
+}
+
+func (c *WorktreeAlreadyExists) Error() string {
+	return fmt.Sprintf("worktree `%s` already exists in project `%s`", c.Path, c.Project)
+}
+
 type FailedToCreateWorktree struct {
 	Path   string
 	Output string
@@ -183,6 +250,233 @@ func (c *FailedToRemoveWorktree) Error() string {
 	return fmt.Sprintf("failed to remove worktree `%s`: %s - %s", c.Path, c.Err, c.Output)
 }
 
+type DuplicateResource struct {
+	Kind    string
+	Name    string
+	Context string
+	Line    int
+}
+
+func (c *DuplicateResource) Error() string {
+	if c.Line > 0 {
+		return fmt.Sprintf("duplicate %s `%s`, already declared in %s:%d", c.Kind, c.Name, c.Context, c.Line)
+	}
+	return fmt.Sprintf("duplicate %s `%s`, already declared in %s", c.Kind, c.Name, c.Context)
+}
+
+type InvalidRemoteImport struct {
+	Source string
+	Reason string
+}
+
+func (c *InvalidRemoteImport) Error() string {
+	return fmt.Sprintf("invalid import `%s`: %s", c.Source, c.Reason)
+}
+
+type FailedToFetchImport struct {
+	Source string
+	Err    error
+}
+
+func (c *FailedToFetchImport) Error() string {
+	return fmt.Sprintf("failed to fetch import `%s`: %s", c.Source, c.Err)
+}
+
+type MissingImportPath struct{}
+
+func (c *MissingImportPath) Error() string {
+	return "missing import `path`"
+}
+
+type InvalidImportNamespace struct {
+	Namespace string
+}
+
+func (c *InvalidImportNamespace) Error() string {
+	return fmt.Sprintf("invalid import namespace `%s`, only letters, digits, `-` and `_` are allowed", c.Namespace)
+}
+
+type AmbiguousName struct {
+	Kind    string
+	Name    string
+	Matches []string
+}
+
+func (c *AmbiguousName) Error() string {
+	return fmt.Sprintf("%s `%s` is ambiguous, use one of `%s`", c.Kind, c.Name, strings.Join(c.Matches, "`, `"))
+}
+
+type MissingEnvFilePath struct{}
+
+func (c *MissingEnvFilePath) Error() string {
+	return "missing env file `path`"
+}
+
+type EnvFileNotFound struct {
+	Path string
+}
+
+func (c *EnvFileNotFound) Error() string {
+	return fmt.Sprintf("cannot find env file `%s`", c.Path)
+}
+
+type EnvFileInvalid struct {
+	Path   string
+	Line   int
+	Reason string
+}
+
+func (c *EnvFileInvalid) Error() string {
+	return fmt.Sprintf("invalid env file `%s:%d`, %s", c.Path, c.Line, c.Reason)
+}
+
+type ConfigReadOnly struct {
+	Path string
+}
+
+func (c *ConfigReadOnly) Error() string {
+	return fmt.Sprintf("cannot edit `%s`, cached remote imports are replaced when fetched", c.Path)
+}
+
+type ProjectFieldInvalid struct {
+	Key    string
+	Fields []string
+}
+
+func (c *ProjectFieldInvalid) Error() string {
+	return fmt.Sprintf("invalid project field `%s`, expected one of: %s", c.Key, strings.Join(c.Fields, ", "))
+}
+
+type ProjectFieldValueInvalid struct {
+	Key   string
+	Value string
+}
+
+func (c *ProjectFieldValueInvalid) Error() string {
+	return fmt.Sprintf("invalid value `%s` for project field `%s`, expected true or false", c.Value, c.Key)
+}
+
+type TagAlreadyExists struct {
+	Project string
+	Tag     string
+}
+
+func (c *TagAlreadyExists) Error() string {
+	return fmt.Sprintf("project `%s` already has tag `%s`", c.Project, c.Tag)
+}
+
+type FormatFailed struct {
+	Path   string
+	Reason string
+}
+
+func (c *FormatFailed) Error() string {
+	return fmt.Sprintf("cannot format `%s`, the formatted config is invalid: %s", c.Path, c.Reason)
+}
+
+type ConfigNotYAML struct {
+	Path string
+}
+
+func (c *ConfigNotYAML) Error() string {
+	return fmt.Sprintf("cannot edit `%s`, only YAML config files can be edited", c.Path)
+}
+
+type TOMLKeyRedefined struct {
+	Key  string
+	Line int
+}
+
+func (c *TOMLKeyRedefined) Error() string {
+	return fmt.Sprintf("toml: line %d: key `%s` is already defined", c.Line, c.Key)
+}
+
+type ConfigVersionUnsupported struct {
+	Version   int
+	Supported int
+}
+
+func (c *ConfigVersionUnsupported) Error() string {
+	return fmt.Sprintf("config version %d is not supported, this version of mani supports up to version %d, upgrade mani", c.Version, c.Supported)
+}
+
+type CheckOutputInvalid struct {
+	Output string
+}
+
+func (c *CheckOutputInvalid) Error() string {
+	return fmt.Sprintf("invalid output `%s`, expected one of: text, json", c.Output)
+}
+
+type SecretProviderInvalid struct {
+	Name string
+}
+
+func (c *SecretProviderInvalid) Error() string {
+	return fmt.Sprintf("secret `%s` must set exactly one of: command, file, env, keyring", c.Name)
+}
+
+type SecretNotFound struct {
+	Name []string
+}
+
+func (c *SecretNotFound) Error() string {
+	secrets := "`" + strings.Join(c.Name, "`, `") + "`"
+	return fmt.Sprintf("cannot find secrets %s", secrets)
+}
+
+type SecretFailed struct {
+	Name string
+	Err  error
+}
+
+func (c *SecretFailed) Error() string {
+	return fmt.Sprintf("failed to resolve secret `%s`: %s", c.Name, c.Err)
+}
+
+type EnvNotSet struct {
+	Name string
+}
+
+func (c *EnvNotSet) Error() string {
+	return fmt.Sprintf("env `%s` is not set", c.Name)
+}
+
+type ManifestFormatUnknown struct {
+	Path string
+}
+
+func (c *ManifestFormatUnknown) Error() string {
+	return fmt.Sprintf("cannot detect manifest format of `%s`, set it with --format", c.Path)
+}
+
+type ManifestFormatInvalid struct {
+	Format  string
+	Formats []string
+}
+
+func (c *ManifestFormatInvalid) Error() string {
+	return fmt.Sprintf("invalid manifest format `%s`, expected one of: %s", c.Format, strings.Join(c.Formats, ", "))
+}
+
+type ExportFormatInvalid struct {
+	Format  string
+	Formats []string
+}
+
+func (c *ExportFormatInvalid) Error() string {
+	return fmt.Sprintf("invalid export format `%s`, expected one of: %s", c.Format, strings.Join(c.Formats, ", "))
+}
+
+type FailedToParseManifest struct {
+	Path string
+	Err  error
+}
+
+func (c *FailedToParseManifest) Error() string {
+	return fmt.Sprintf("failed to parse manifest `%s`: %s", c.Path, c.Err)
+}
+
 type ConfigErr struct {
 	Msg string
 }
@@ -191,6 +485,19 @@ func (f *ConfigErr) Error() string {
 	return f.Msg
 }
 
+// ErrorMessages returns the messages of err without colors, config errors
+// contain one message per resource error
+func ErrorMessages(err error) []string {
+	var msgs []string
+	for msg := range strings.SplitSeq(color.ClearCode(err.Error()), "\n\n") {
+		if msg = strings.TrimSpace(msg); msg != "" {
+			msgs = append(msgs, strings.TrimPrefix(msg, "error: "))
+		}
+	}
+
+	return msgs
+}
+
 func CheckIfError(err error) {
 	if err != nil {
 		switch err.(type) {
@@ -0,0 +1,349 @@
+package exec
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/gookit/color"
+
+	"github.com/alajmo/mani/core"
+	"github.com/alajmo/mani/core/dao"
+	"github.com/alajmo/mani/core/print"
+)
+
+const (
+	BranchCreated  = "created"
+	BranchExists   = "exists"
+	BranchSwitched = "switched"
+	BranchCurrent  = "current"
+	BranchDeleted  = "deleted"
+	BranchMissing  = "missing"
+	BranchDirty    = "dirty"
+	BranchClean    = "clean"
+	BranchFailed   = "failed"
+)
+
+// BranchResult is the outcome of a branch operation for a single project
+type BranchResult struct {
+	Project string
+	Branch  string
+	Status  string
+	Message string
+}
+
+func (b BranchResult) GetValue(key string, _ int) string {
+	switch key {
+	case "project":
+		return b.Project
+	case "branch":
+		return b.Branch
+	case "status":
+		switch b.Status {
+		case BranchCreated, BranchSwitched, BranchDeleted, BranchClean:
+			return color.FgGreen.Sprint(b.Status)
+		case BranchExists, BranchCurrent:
+			return color.FgBlue.Sprint(b.Status)
+		case BranchMissing, BranchDirty:
+			return color.FgYellow.Sprint(b.Status)
+		default:
+			return color.FgRed.Sprint(b.Status)
+		}
+	case "message", "upstream":
+		return b.Message
+	default:
+		return ""
+	}
+}
+
+func runGit(path string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = path
+	output, err := cmd.CombinedOutput()
+	return strings.TrimSpace(string(output)), err
+}
+
+func localBranchExists(path string, branch string) bool {
+	_, err := runGit(path, "show-ref", "--verify", "--quiet", "refs/heads/"+branch)
+	return err == nil
+}
+
+func remoteBranchExists(path string, branch string) bool {
+	output, err := runGit(path, "for-each-ref", "--format=%(refname)", "refs/remotes/*/"+branch)
+	return err == nil && output != ""
+}
+
+// branchPreCheck returns a result if the project is not cloned, along with the
+// current branch of the project
+func branchPreCheck(project dao.Project, branch string) (*BranchResult, string) {
+	result := BranchResult{Project: project.Name, Branch: branch}
+
+	if _, err := os.Stat(project.Path); os.IsNotExist(err) {
+		result.Status = BranchMissing
+		result.Message = "project not cloned"
+		return &result, ""
+	}
+
+	current, err := core.GetCurrentBranch(project.Path)
+	if err != nil {
+		result.Status = BranchFailed
+		result.Message = err.Error()
+		return &result, ""
+	}
+
+	return nil, current
+}
+
+// skipDirty sets the result status and returns true if the project has
+// uncommitted changes and force is not set
+func skipDirty(project dao.Project, result *BranchResult, force bool) bool {
+	if force {
+		return false
+	}
+
+	dirty, err := core.IsGitDirty(project.Path)
+	if err != nil {
+		result.Status = BranchFailed
+		result.Message = err.Error()
+		return true
+	}
+
+	if dirty {
+		result.Status = BranchDirty
+		result.Message = "uncommitted changes, use --force to ignore"
+		return true
+	}
+
+	return false
+}
+
+func pushBranch(project dao.Project, branch string, remote string) error {
+	output, err := runGit(project.Path, "push", "--set-upstream", remote, branch)
+	if err != nil {
+		return fmt.Errorf("%s", output)
+	}
+
+	return nil
+}
+
+// CreateBranch creates and checks out a branch in each project, projects
+// which already have the branch check it out. If push is set, the branch is
+// pushed and set to track the remote branch.
+func CreateBranch(projects []dao.Project, branch string, flags core.BranchFlags) []BranchResult {
+	var results []BranchResult
+
+	for _, project := range projects {
+		result, current := branchPreCheck(project, branch)
+		if result != nil {
+			results = append(results, *result)
+			continue
+		}
+
+		result = &BranchResult{Project: project.Name, Branch: branch}
+		exists := localBranchExists(project.Path, branch)
+		switch {
+		case exists && current == branch:
+			result.Status = BranchExists
+			result.Message = "already checked out"
+		case skipDirty(project, result, flags.Force):
+			// Status is set by skipDirty
+		case exists:
+			output, err := runGit(project.Path, "checkout", branch)
+			if err != nil {
+				result.Status = BranchFailed
+				result.Message = output
+			} else {
+				result.Status = BranchSwitched
+				result.Message = fmt.Sprintf("branch exists, switched from %s", current)
+			}
+		default:
+			output, err := runGit(project.Path, "checkout", "-b", branch)
+			if err != nil {
+				result.Status = BranchFailed
+				result.Message = output
+			} else {
+				result.Status = BranchCreated
+			}
+		}
+
+		if flags.Push && (result.Status == BranchCreated || result.Status == BranchSwitched || result.Status == BranchExists) {
+			if err := pushBranch(project, branch, flags.Remote); err != nil {
+				result.Message = fmt.Sprintf("%s, failed to push: %s", result.Status, err)
+				result.Status = BranchFailed
+			} else {
+				result.Message = fmt.Sprintf("pushed to %s", flags.Remote)
+			}
+		}
+
+		results = append(results, *result)
+	}
+
+	return results
+}
+
+// CheckoutBranch switches each project to an existing local or remote branch
+func CheckoutBranch(projects []dao.Project, branch string, flags core.BranchFlags) []BranchResult {
+	var results []BranchResult
+
+	for _, project := range projects {
+		result, current := branchPreCheck(project, branch)
+		if result != nil {
+			results = append(results, *result)
+			continue
+		}
+
+		result = &BranchResult{Project: project.Name, Branch: branch}
+		switch {
+		case current == branch:
+			result.Status = BranchCurrent
+		case !localBranchExists(project.Path, branch) && !remoteBranchExists(project.Path, branch):
+			result.Status = BranchMissing
+			result.Message = "branch not found"
+		case skipDirty(project, result, flags.Force):
+			// Status is set by skipDirty
+		default:
+			output, err := runGit(project.Path, "checkout", branch)
+			if err != nil {
+				result.Status = BranchFailed
+				result.Message = output
+			} else {
+				result.Status = BranchSwitched
+				result.Message = fmt.Sprintf("from %s", current)
+			}
+		}
+
+		if flags.Push && (result.Status == BranchSwitched || result.Status == BranchCurrent) {
+			if err := pushBranch(project, branch, flags.Remote); err != nil {
+				result.Status = BranchFailed
+				result.Message = fmt.Sprintf("failed to push: %s", err)
+			} else {
+				result.Message = fmt.Sprintf("pushed to %s", flags.Remote)
+			}
+		}
+
+		results = append(results, *result)
+	}
+
+	return results
+}
+
+// DeleteBranch deletes a local branch in each project. If push is set, the
+// branch is also deleted from the remote.
+func DeleteBranch(projects []dao.Project, branch string, flags core.BranchFlags) []BranchResult {
+	var results []BranchResult
+
+	for _, project := range projects {
+		result, current := branchPreCheck(project, branch)
+		if result != nil {
+			results = append(results, *result)
+			continue
+		}
+
+		result = &BranchResult{Project: project.Name, Branch: branch}
+		switch {
+		case current == branch:
+			result.Status = BranchFailed
+			result.Message = "branch is checked out"
+		case !localBranchExists(project.Path, branch):
+			result.Status = BranchMissing
+			result.Message = "branch not found"
+		default:
+			deleteFlag := "-d"
+			if flags.Force {
+				deleteFlag = "-D"
+			}
+
+			output, err := runGit(project.Path, "branch", deleteFlag, branch)
+			if err != nil {
+				result.Status = BranchFailed
+				result.Message = output
+			} else {
+				result.Status = BranchDeleted
+			}
+		}
+
+		if flags.Push && (result.Status == BranchDeleted || result.Status == BranchMissing) {
+			output, err := runGit(project.Path, "push", flags.Remote, "--delete", branch)
+			if err != nil {
+				if result.Status == BranchDeleted {
+					result.Message = fmt.Sprintf("failed to delete on %s: %s", flags.Remote, output)
+					result.Status = BranchFailed
+				}
+			} else {
+				result.Status = BranchDeleted
+				result.Message = fmt.Sprintf("deleted on %s", flags.Remote)
+			}
+		}
+
+		results = append(results, *result)
+	}
+
+	return results
+}
+
+// ListBranches returns the current branch, dirty state and upstream of each project
+func ListBranches(projects []dao.Project) []BranchResult {
+	var results []BranchResult
+
+	for _, project := range projects {
+		result, current := branchPreCheck(project, "")
+		if result != nil {
+			results = append(results, *result)
+			continue
+		}
+
+		result = &BranchResult{Project: project.Name, Branch: current, Status: BranchClean}
+		if current == "" {
+			result.Branch = "(detached)"
+		}
+
+		dirty, err := core.IsGitDirty(project.Path)
+		if err != nil {
+			result.Status = BranchFailed
+			result.Message = err.Error()
+			results = append(results, *result)
+			continue
+		}
+		if dirty {
+			result.Status = BranchDirty
+		}
+
+		if upstream, err := runGit(project.Path, "rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{upstream}"); err == nil {
+			result.Message = upstream
+		}
+
+		results = append(results, *result)
+	}
+
+	return results
+}
+
+// HasBranchFailures returns true if any of the branch operations failed
+func HasBranchFailures(results []BranchResult) bool {
+	for _, result := range results {
+		if result.Status == BranchFailed {
+			return true
+		}
+	}
+
+	return false
+}
+
+func PrintBranchResults(results []BranchResult, theme dao.Theme, output string, headers []string) {
+	theme.Table.Border.Rows = core.Ptr(false)
+	theme.Table.Header.Format = core.Ptr("t")
+
+	options := print.PrintTableOptions{
+		Theme:            theme,
+		Output:           output,
+		Color:            *theme.Color,
+		AutoWrap:         true,
+		OmitEmptyRows:    false,
+		OmitEmptyColumns: false,
+	}
+
+	fmt.Println()
+	print.PrintTable(results, options, headers, []string{}, os.Stdout)
+	fmt.Println()
+}
@@ -0,0 +1,100 @@
+package exec
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alajmo/mani/core"
+)
+
+func TestBranch_Create(t *testing.T) {
+	gitEnv(t)
+	dir := t.TempDir()
+
+	initRepo(t, filepath.Join(dir, "api"), "")
+	initRepo(t, filepath.Join(dir, "web"), "")
+	initRepo(t, filepath.Join(dir, "docs"), "")
+	testGit(t, filepath.Join(dir, "web"), "branch", "feature")
+	testGit(t, filepath.Join(dir, "docs"), "checkout", "--quiet", "-b", "feature")
+
+	config := readTestConfig(t, dir, `
+projects:
+  api:
+  web:
+  docs:
+  lib:
+    url: git@example.com:lib.git
+`)
+
+	results := CreateBranch(config.ProjectList, "feature", core.BranchFlags{})
+
+	// The branch is created, or checked out if it already exists
+	expected := map[string]string{
+		"api":  BranchCreated,
+		"web":  BranchSwitched,
+		"docs": BranchExists,
+		"lib":  BranchMissing,
+	}
+	for _, result := range results {
+		if result.Status != expected[result.Project] {
+			t.Errorf("%s: expected status %s, got %s (%s)", result.Project, expected[result.Project], result.Status, result.Message)
+		}
+	}
+	for _, name := range []string{"api", "web", "docs"} {
+		if branch, _ := core.GetCurrentBranch(filepath.Join(dir, name)); branch != "feature" {
+			t.Errorf("%s: expected branch feature to be checked out, got %s", name, branch)
+		}
+	}
+
+	// Projects with uncommitted changes are skipped unless forced
+	err := os.WriteFile(filepath.Join(dir, "api", "file"), []byte("change"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results = CreateBranch(config.ProjectList[:1], "other", core.BranchFlags{})
+	if results[0].Status != BranchDirty {
+		t.Errorf("expected dirty project to be skipped, got %s", results[0].Status)
+	}
+	results = CreateBranch(config.ProjectList[:1], "main", core.BranchFlags{Force: true})
+	if results[0].Status != BranchSwitched {
+		t.Errorf("expected forced checkout of existing branch, got %s (%s)", results[0].Status, results[0].Message)
+	}
+}
+
+func TestBranch_CheckoutAndDelete(t *testing.T) {
+	gitEnv(t)
+	dir := t.TempDir()
+
+	initRepo(t, filepath.Join(dir, "api"), "")
+	testGit(t, filepath.Join(dir, "api"), "branch", "feature")
+
+	config := readTestConfig(t, dir, "projects:\n  api:\n")
+
+	results := CheckoutBranch(config.ProjectList, "feature", core.BranchFlags{})
+	if results[0].Status != BranchSwitched || results[0].Message != "from main" {
+		t.Errorf("expected switch from main, got %+v", results[0])
+	}
+
+	results = CheckoutBranch(config.ProjectList, "missing", core.BranchFlags{})
+	if results[0].Status != BranchMissing {
+		t.Errorf("expected missing branch, got %+v", results[0])
+	}
+
+	// The checked out branch can't be deleted
+	results = DeleteBranch(config.ProjectList, "feature", core.BranchFlags{})
+	if results[0].Status != BranchFailed || !HasBranchFailures(results) {
+		t.Errorf("expected deleting the checked out branch to fail, got %+v", results[0])
+	}
+
+	CheckoutBranch(config.ProjectList, "main", core.BranchFlags{})
+	results = DeleteBranch(config.ProjectList, "feature", core.BranchFlags{})
+	if results[0].Status != BranchDeleted {
+		t.Errorf("expected branch to be deleted, got %+v", results[0])
+	}
+
+	results = ListBranches(config.ProjectList)
+	if results[0].Branch != "main" || results[0].Status != BranchClean {
+		t.Errorf("expected clean main branch, got %+v", results[0])
+	}
+}
@@ -5,13 +5,18 @@ import (
 	"io"
 	"os"
 	"os/exec"
+	"strings"
+
+	"github.com/alajmo/mani/core"
+	"github.com/alajmo/mani/core/dao"
 )
 
 // Client is a wrapper over the SSH connection/sessions.
 type Client struct {
-	Name string
-	Path string
-	Env  []string
+	Name    string
+	Path    string
+	Env     []string
+	Secrets []string // passed to the command, env takes precedence
 
 	cmd     *exec.Cmd
 	stdout  io.Reader
@@ -28,20 +33,32 @@ func (c *Client) Run(shell string, env []string, cmdStr []string) error {
 	cmd := exec.Command(shell, cmdStr...)
 
 	cmd.Dir = c.Path
-	cmd.Env = append(os.Environ(), env...)
+	cmd.Env = append(append(os.Environ(), c.Secrets...), env...)
 
 	c.cmd = cmd
 
-	c.stdout, err = cmd.StdoutPipe()
+	stdout, err := cmd.StdoutPipe()
 	if err != nil {
 		return err
 	}
 
-	c.stderr, err = cmd.StderrPipe()
+	stderr, err := cmd.StderrPipe()
 	if err != nil {
 		return err
 	}
 
+	// Secret values are replaced in the command output
+	c.stdout = stdout
+	c.stderr = stderr
+	if len(c.Secrets) > 0 {
+		values := make([]string, len(c.Secrets))
+		for i, secret := range c.Secrets {
+			_, values[i], _ = strings.Cut(secret, "=")
+		}
+		c.stdout = core.NewRedactor(stdout, values, dao.REDACTED)
+		c.stderr = core.NewRedactor(stderr, values, dao.REDACTED)
+	}
+
 	if err := c.cmd.Start(); err != nil {
 		return err
 	}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/alajmo/mani/core"
@@ -151,9 +152,14 @@ func GetWorktrees(parentPath string) (map[string]string, error) {
 	return core.GetWorktreeList(parentPath)
 }
 
-// RemoveWorktree removes a git worktree (keeps the branch)
-func RemoveWorktree(parentPath string, worktreePath string) error {
-	cmd := exec.Command("git", "worktree", "remove", worktreePath)
+// RemoveWorktree removes a git worktree (keeps the branch). Unless force is set,
+// git refuses to remove worktrees with uncommitted changes.
+func RemoveWorktree(parentPath string, worktreePath string, force bool) error {
+	args := []string{"worktree", "remove", worktreePath}
+	if force {
+		args = append(args, "--force")
+	}
+	cmd := exec.Command("git", args...)
 	cmd.Dir = parentPath
 	output, err := cmd.CombinedOutput()
 	if err != nil {
@@ -179,58 +185,78 @@ func SyncWorktrees(config *dao.Config, project dao.Project, removeOrphans bool)
 	pruneCmd.Dir = parentPath
 	_ = pruneCmd.Run()
 
+	create, remove, err := getWorktreeChanges(parentPath, project, removeOrphans)
+	if err != nil {
+		return err
+	}
+
+	for _, wt := range create {
+		// Try checking out existing branch first (local or remote-tracking)
+		err = CreateWorktree(parentPath, wt.Path, wt.Branch, false)
+		if err != nil {
+			// Branch doesn't exist anywhere — create it
+			err = CreateWorktree(parentPath, wt.Path, wt.Branch, true)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	// Remove worktrees not in config (only if enabled)
+	for _, wtPath := range remove {
+		err := RemoveWorktree(parentPath, wtPath, false)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getWorktreeChanges returns the worktrees (with absolute paths) which don't exist yet,
+// and if removeOrphans is set, the paths of the existing worktrees not in the config
+func getWorktreeChanges(parentPath string, project dao.Project, removeOrphans bool) ([]dao.Worktree, []string, error) {
+	var create []dao.Worktree
+	var remove []string
+
 	// Build map of expected worktree paths from config
 	expectedPaths := make(map[string]bool)
 	for _, wt := range project.WorktreeList {
-		var wtPath string
 		if filepath.IsAbs(wt.Path) {
-			wtPath = filepath.Clean(wt.Path)
+			wt.Path = filepath.Clean(wt.Path)
 		} else {
-			wtPath = filepath.Join(parentPath, wt.Path)
+			wt.Path = filepath.Join(parentPath, wt.Path)
 		}
-		expectedPaths[wtPath] = true
+		expectedPaths[wt.Path] = true
 
-		// Create worktree if it doesn't exist
-		if _, err := os.Stat(wtPath); os.IsNotExist(err) {
-			// Try checking out existing branch first (local or remote-tracking)
-			err = CreateWorktree(parentPath, wtPath, wt.Branch, false)
-			if err != nil {
-				// Branch doesn't exist anywhere — create it
-				err = CreateWorktree(parentPath, wtPath, wt.Branch, true)
-			}
-			if err != nil {
-				return err
-			}
+		if _, err := os.Stat(wt.Path); os.IsNotExist(err) {
+			create = append(create, wt)
 		}
 	}
 
-	// Remove worktrees not in config (only if enabled)
-	if removeOrphans {
-		existingWorktrees, err := GetWorktrees(parentPath)
-		if err != nil {
-			return err
-		}
+	// A project which is not cloned yet has no worktrees to remove
+	if _, err := os.Stat(parentPath); os.IsNotExist(err) || !removeOrphans {
+		return create, remove, nil
+	}
 
-		for wtPath := range existingWorktrees {
-			if !expectedPaths[wtPath] {
-				err := RemoveWorktree(parentPath, wtPath)
-				if err != nil {
-					return err
-				}
-			}
+	existingWorktrees, err := GetWorktrees(parentPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for wtPath := range existingWorktrees {
+		if _, err := os.Stat(wtPath); !expectedPaths[wtPath] && err == nil {
+			remove = append(remove, wtPath)
 		}
 	}
+	slices.Sort(remove)
 
-	return nil
+	return create, remove, nil
 }
 
-func CloneRepos(config *dao.Config, projects []dao.Project, syncFlags core.SyncFlags) error {
-	urls := config.GetProjectUrls()
-	if len(urls) == 0 {
-		fmt.Println("No projects to clone")
-		return nil
-	}
-
+// getCloneProjects returns the projects which sync clones, that is projects
+// with a url which don't exist yet
+func getCloneProjects(config *dao.Config, projects []dao.Project, syncFlags core.SyncFlags) ([]dao.Project, error) {
 	var syncProjects []dao.Project
 	for i := range projects {
 		if !syncFlags.IgnoreSyncState && !projects[i].IsSync() {
@@ -243,7 +269,7 @@ func CloneRepos(config *dao.Config, projects []dao.Project, syncFlags core.SyncF
 
 		projectPath, err := core.GetAbsolutePath(config.Path, projects[i].Path, projects[i].Name)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		// Project already synced
@@ -254,6 +280,21 @@ func CloneRepos(config *dao.Config, projects []dao.Project, syncFlags core.SyncF
 		syncProjects = append(syncProjects, projects[i])
 	}
 
+	return syncProjects, nil
+}
+
+func CloneRepos(config *dao.Config, projects []dao.Project, syncFlags core.SyncFlags) error {
+	urls := config.GetProjectUrls()
+	if len(urls) == 0 {
+		fmt.Println("No projects to clone")
+		return nil
+	}
+
+	syncProjects, err := getCloneProjects(config, projects, syncFlags)
+	if err != nil {
+		return err
+	}
+
 	var tasks []dao.Task
 	for i := range syncProjects {
 		var cmd string
@@ -324,7 +365,7 @@ func CloneRepos(config *dao.Config, projects []dao.Project, syncFlags core.SyncF
 	if len(syncProjects) > 0 {
 		target := Exec{Projects: syncProjects, Tasks: tasks, Config: *config}
 		clientCh := make(chan Client, len(syncProjects))
-		err := target.SetCloneClients(clientCh)
+		err = target.SetCloneClients(clientCh)
 		if err != nil {
 			return err
 		}
@@ -357,6 +398,107 @@ func CloneRepos(config *dao.Config, projects []dao.Project, syncFlags core.SyncF
 	return nil
 }
 
+// PrintSyncPlan prints what sync would do, without cloning or modifying any projects
+func PrintSyncPlan(config *dao.Config, projects []dao.Project, syncFlags core.SyncFlags) error {
+	rows, err := getSyncPlan(config, projects, syncFlags)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	if *config.SyncGitignore {
+		gitignoreFilename := filepath.Join(config.Dir, ".gitignore")
+		if _, err := os.Stat(gitignoreFilename); err == nil {
+			fmt.Printf("%s would be updated with the project paths (dry run)\n", gitignoreFilename)
+			fmt.Println()
+		}
+	}
+
+	if len(rows) == 0 {
+		fmt.Println("No projects to sync (dry run)")
+		return nil
+	}
+
+	theme := dao.Theme{
+		Color: core.Ptr(true),
+		Table: dao.DefaultTable,
+	}
+	theme.Table.Border.Rows = core.Ptr(false)
+	theme.Table.Header.Format = core.Ptr("t")
+
+	options := print.PrintTableOptions{
+		Theme:            theme,
+		Output:           "table",
+		Color:            *theme.Color,
+		AutoWrap:         true,
+		OmitEmptyRows:    false,
+		OmitEmptyColumns: false,
+	}
+
+	fmt.Println("Following projects would be synced (dry run)")
+	fmt.Println()
+	print.PrintTable(rows, options, []string{"project", "action"}, []string{}, os.Stdout)
+
+	return nil
+}
+
+// getSyncPlan returns a row for each project sync would modify, listing the
+// actions in the same order CloneRepos runs them
+func getSyncPlan(config *dao.Config, projects []dao.Project, syncFlags core.SyncFlags) ([]dao.Row, error) {
+	cloneProjects, err := getCloneProjects(config, projects, syncFlags)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []dao.Row
+	for _, project := range projects {
+		var actions []string
+
+		clone := slices.ContainsFunc(cloneProjects, func(p dao.Project) bool { return p.Name == project.Name })
+		if clone {
+			if project.Clone != "" {
+				actions = append(actions, fmt.Sprintf("clone with %s", project.Clone))
+			} else {
+				actions = append(actions, fmt.Sprintf("clone %s", project.URL))
+			}
+		}
+
+		if *config.SyncRemotes && len(project.RemoteList) > 0 {
+			actions = append(actions, "sync remotes")
+		}
+
+		if len(project.WorktreeList) > 0 || *config.RemoveOrphanedWorktrees {
+			parentPath, err := core.GetAbsolutePath(config.Path, project.Path, project.Name)
+			if err != nil {
+				return nil, err
+			}
+
+			create, remove, err := getWorktreeChanges(parentPath, project, *config.RemoveOrphanedWorktrees)
+			if err != nil {
+				return nil, err
+			}
+
+			// Worktrees are only synced for projects which exist after cloning
+			if _, err := os.Stat(parentPath); os.IsNotExist(err) && !clone {
+				create = nil
+			}
+
+			for _, wt := range create {
+				actions = append(actions, fmt.Sprintf("add worktree %s (%s)", wt.Path, wt.Branch))
+			}
+			for _, wtPath := range remove {
+				actions = append(actions, fmt.Sprintf("remove worktree %s", wtPath))
+			}
+		}
+
+		if len(actions) > 0 {
+			rows = append(rows, dao.Row{Columns: []string{project.Name, strings.Join(actions, "\n")}})
+		}
+	}
+
+	return rows, nil
+}
+
 func UpdateGitignoreIfExists(config *dao.Config) error {
 	// Only add projects to gitignore if a .gitignore file exists in the mani.yaml directory
 	gitignoreFilename := filepath.Join(filepath.Dir(config.Path), ".gitignore")
@@ -430,6 +572,11 @@ func (exec *Exec) SetCloneClients(clientCh chan Client) error {
 	config := exec.Config
 	projects := exec.Projects
 
+	err := dao.ParseProjectsEnv(projects)
+	if err != nil {
+		return err
+	}
+
 	var clients []Client
 	for i, project := range projects {
 		func(i int, project dao.Project) {
@@ -528,3 +675,81 @@ func PrintProjectInit(projects []dao.Project) {
 	fmt.Println()
 	print.PrintTable(data.Rows, options, data.Headers, []string{}, os.Stdout)
 }
+
+func PrintProjectUpdate(projects []dao.Project) {
+	theme := dao.Theme{
+		Table: dao.DefaultTable,
+		Color: core.Ptr(true),
+	}
+	theme.Table.Border.Rows = core.Ptr(false)
+	theme.Table.Header.Format = core.Ptr("t")
+
+	options := print.PrintTableOptions{
+		Theme:            theme,
+		Output:           "table",
+		Color:            true,
+		AutoWrap:         true,
+		OmitEmptyRows:    true,
+		OmitEmptyColumns: true,
+	}
+
+	data := dao.TableOutput{
+		Headers: []string{"project", "path", "worktrees"},
+		Rows:    []dao.Row{},
+	}
+
+	for _, project := range projects {
+		data.Rows = append(data.Rows, dao.Row{Columns: []string{project.Name, project.Path, project.GetValue("worktrees", 0)}})
+	}
+
+	fmt.Println("\nFollowing projects and worktrees were added to mani.yaml")
+	fmt.Println()
+	print.PrintTable(data.Rows, options, data.Headers, []string{}, os.Stdout)
+}
+
+func PrintProjectImport(added []dao.Project, skipped []dao.Project) {
+	theme := dao.Theme{
+		Table: dao.DefaultTable,
+		Color: core.Ptr(true),
+	}
+	theme.Table.Border.Rows = core.Ptr(false)
+	theme.Table.Header.Format = core.Ptr("t")
+
+	options := print.PrintTableOptions{
+		Theme:            theme,
+		Output:           "table",
+		Color:            true,
+		AutoWrap:         true,
+		OmitEmptyRows:    true,
+		OmitEmptyColumns: true,
+	}
+
+	data := dao.TableOutput{
+		Headers: []string{"project", "path", "url", "branch", "tags"},
+		Rows:    []dao.Row{},
+	}
+
+	for _, project := range added {
+		data.Rows = append(data.Rows, dao.Row{Columns: []string{
+			project.Name,
+			project.Path,
+			project.URL,
+			project.Branch,
+			project.GetValue("tag", 0),
+		}})
+	}
+
+	if len(added) > 0 {
+		fmt.Println("\nFollowing projects were added to mani.yaml")
+		fmt.Println()
+		print.PrintTable(data.Rows, options, data.Headers, []string{}, os.Stdout)
+	}
+
+	if len(skipped) > 0 {
+		fmt.Println("\nFollowing projects are already declared and were skipped")
+		fmt.Println()
+		for _, project := range skipped {
+			fmt.Printf("  %s\n", project.Path)
+		}
+	}
+}
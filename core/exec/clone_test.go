@@ -0,0 +1,63 @@
+package exec
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/alajmo/mani/core"
+	"github.com/alajmo/mani/core/dao"
+)
+
+func TestSync_Plan(t *testing.T) {
+	gitEnv(t)
+	dir := t.TempDir()
+
+	api := filepath.Join(dir, "api")
+	initRepo(t, api, "git@example.com:api.git")
+	testGit(t, api, "worktree", "add", "--quiet", "-b", "old", filepath.Join(api, "wt/old"))
+
+	config := readTestConfig(t, dir, `
+sync_remotes: true
+remove_orphaned_worktrees: true
+
+projects:
+  api:
+    url: git@example.com:api.git
+    worktrees:
+      - path: wt/feature
+  web:
+    url: git@example.com:web.git
+    remotes:
+      upstream: git@example.com:upstream.git
+    worktrees:
+      - path: wt/fix
+  docs:
+    worktrees:
+      - path: wt/draft
+`)
+
+	rows, err := getSyncPlan(&config, config.ProjectList, core.SyncFlags{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []dao.Row{
+		{Columns: []string{"api", "add worktree " + filepath.Join(api, "wt/feature") + " (feature)\nremove worktree " + filepath.Join(api, "wt/old")}},
+		{Columns: []string{"web", "clone git@example.com:web.git\nsync remotes\nadd worktree " + filepath.Join(dir, "web/wt/fix") + " (fix)"}},
+	}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Errorf("expected %v, got %v", expected, rows)
+	}
+
+	// The plan doesn't modify anything
+	for _, path := range []string{filepath.Join(dir, "web"), filepath.Join(api, "wt/feature")} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("expected %s not to exist", path)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(api, "wt/old")); err != nil {
+		t.Errorf("expected worktree wt/old to be kept: %v", err)
+	}
+}
@@ -0,0 +1,333 @@
+package exec
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/gookit/color"
+	"gopkg.in/yaml.v3"
+
+	"github.com/alajmo/mani/core"
+	"github.com/alajmo/mani/core/dao"
+	"github.com/alajmo/mani/core/print"
+)
+
+const (
+	DiffMissingClone       = "missing clone"
+	DiffUndeclaredRepo     = "undeclared repository"
+	DiffURL                = "url"
+	DiffMissingRemote      = "missing remote"
+	DiffUndeclaredRemote   = "undeclared remote"
+	DiffRemoteURL          = "remote url"
+	DiffBranch             = "branch"
+	DiffMissingWorktree    = "missing worktree"
+	DiffUndeclaredWorktree = "undeclared worktree"
+)
+
+// ProjectDiff describes a single difference between the config and the disk
+type ProjectDiff struct {
+	Project string
+	Kind    string
+	Name    string // Remote name, worktree path or repository path, depending on Kind
+	Config  string
+	Disk    string
+}
+
+func (d ProjectDiff) GetValue(key string, _ int) string {
+	switch key {
+	case "project":
+		return d.Project
+	case "difference":
+		if d.Name != "" {
+			return fmt.Sprintf("%s `%s`", d.Kind, d.Name)
+		}
+		return d.Kind
+	case "config":
+		return d.Config
+	case "disk":
+		return d.Disk
+	default:
+		return ""
+	}
+}
+
+// DiffProjects compares the declared projects with the repositories found on disk.
+// If includeUndeclared is set, git repositories below the config directory which
+// are not declared in the config are reported as well.
+func DiffProjects(config *dao.Config, projects []dao.Project, includeUndeclared bool) ([]ProjectDiff, error) {
+	diffs := []ProjectDiff{}
+
+	for _, project := range projects {
+		if _, err := os.Stat(project.Path); os.IsNotExist(err) {
+			diffs = append(diffs, ProjectDiff{Project: project.Name, Kind: DiffMissingClone, Config: project.RelPath})
+			continue
+		}
+
+		// Only git repositories have remotes, branches and worktrees to compare
+		if _, err := os.Stat(filepath.Join(project.Path, ".git")); os.IsNotExist(err) {
+			continue
+		}
+
+		projectDiffs, err := diffProject(project)
+		if err != nil {
+			return diffs, err
+		}
+		diffs = append(diffs, projectDiffs...)
+	}
+
+	if includeUndeclared {
+		orphans, err := config.GetOrphanedProjects([]string{})
+		if err != nil {
+			return diffs, err
+		}
+
+		names := config.GetProjectNames()
+		for _, orphan := range orphans {
+			name := orphan.Name
+			for _, n := range names {
+				if n == name {
+					name = orphan.RelPath
+					break
+				}
+			}
+			names = append(names, name)
+
+			diffs = append(diffs, ProjectDiff{
+				Project: name,
+				Kind:    DiffUndeclaredRepo,
+				Name:    orphan.RelPath,
+				Disk:    orphan.URL,
+			})
+		}
+	}
+
+	return diffs, nil
+}
+
+func diffProject(project dao.Project) ([]ProjectDiff, error) {
+	diffs := []ProjectDiff{}
+
+	remotes, err := getRemotes(project)
+	if err != nil {
+		return diffs, err
+	}
+
+	// Remotes
+	if origin := remotes["origin"]; origin != project.URL {
+		diffs = append(diffs, ProjectDiff{Project: project.Name, Kind: DiffURL, Config: project.URL, Disk: origin})
+	}
+
+	declaredRemotes := map[string]bool{"origin": true}
+	for _, remote := range project.RemoteList {
+		declaredRemotes[remote.Name] = true
+		url, found := remotes[remote.Name]
+		if !found {
+			diffs = append(diffs, ProjectDiff{Project: project.Name, Kind: DiffMissingRemote, Name: remote.Name, Config: remote.URL})
+		} else if url != remote.URL {
+			diffs = append(diffs, ProjectDiff{Project: project.Name, Kind: DiffRemoteURL, Name: remote.Name, Config: remote.URL, Disk: url})
+		}
+	}
+
+	remoteNames := make([]string, 0, len(remotes))
+	for name := range remotes {
+		remoteNames = append(remoteNames, name)
+	}
+	sort.Strings(remoteNames)
+	for _, name := range remoteNames {
+		if !declaredRemotes[name] {
+			diffs = append(diffs, ProjectDiff{Project: project.Name, Kind: DiffUndeclaredRemote, Name: name, Disk: remotes[name]})
+		}
+	}
+
+	// Branch
+	if project.Branch != "" {
+		branch, err := core.GetCurrentBranch(project.Path)
+		if err != nil {
+			return diffs, err
+		}
+
+		if branch != project.Branch {
+			diffs = append(diffs, ProjectDiff{Project: project.Name, Kind: DiffBranch, Config: project.Branch, Disk: branch})
+		}
+	}
+
+	// Worktrees
+	worktrees, err := GetWorktrees(project.Path)
+	if err != nil {
+		return diffs, err
+	}
+
+	declaredWorktrees := make(map[string]bool)
+	for _, wt := range project.WorktreeList {
+		wtPath := wt.Path
+		if !filepath.IsAbs(wtPath) {
+			wtPath = filepath.Join(project.Path, wtPath)
+		}
+		wtPath = filepath.Clean(wtPath)
+		declaredWorktrees[wtPath] = true
+
+		branch, found := worktrees[wtPath]
+		if !found {
+			diffs = append(diffs, ProjectDiff{Project: project.Name, Kind: DiffMissingWorktree, Name: wt.Path, Config: wt.Branch})
+		} else if branch != wt.Branch {
+			diffs = append(diffs, ProjectDiff{Project: project.Name, Kind: DiffBranch, Name: wt.Path, Config: wt.Branch, Disk: branch})
+		}
+	}
+
+	wtPaths := make([]string, 0, len(worktrees))
+	for wtPath := range worktrees {
+		wtPaths = append(wtPaths, wtPath)
+	}
+	sort.Strings(wtPaths)
+	for _, wtPath := range wtPaths {
+		if declaredWorktrees[wtPath] {
+			continue
+		}
+
+		relPath, err := filepath.Rel(project.Path, wtPath)
+		if err != nil {
+			relPath = wtPath
+		}
+		diffs = append(diffs, ProjectDiff{Project: project.Name, Kind: DiffUndeclaredWorktree, Name: relPath, Disk: worktrees[wtPath]})
+	}
+
+	return diffs, nil
+}
+
+// DiffPatch returns a YAML document containing the project fields that need to be
+// merged into the config to match the disk. Missing clones and worktrees are left
+// as-is since `mani sync` creates them.
+func DiffPatch(config *dao.Config, diffs []ProjectDiff) (string, error) {
+	projectsNode := &yaml.Node{Kind: yaml.MappingNode}
+	nodes := make(map[string]*yaml.Node)
+
+	getProjectNode := func(name string) *yaml.Node {
+		if node, found := nodes[name]; found {
+			return node
+		}
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		projectsNode.Content = append(projectsNode.Content, dao.ScalarNode(name), node)
+		nodes[name] = node
+		return node
+	}
+
+	getChildNode := func(parent *yaml.Node, key string, kind yaml.Kind) *yaml.Node {
+		for i := 0; i < len(parent.Content); i += 2 {
+			if parent.Content[i].Value == key {
+				return parent.Content[i+1]
+			}
+		}
+		node := &yaml.Node{Kind: kind}
+		parent.Content = append(parent.Content, dao.ScalarNode(key), node)
+		return node
+	}
+
+	for _, diff := range diffs {
+		switch diff.Kind {
+		case DiffUndeclaredRepo:
+			node := getProjectNode(diff.Project)
+			node.Content = append(node.Content, dao.ScalarNode("path"), dao.ScalarNode(diff.Name))
+			if diff.Disk != "" {
+				node.Content = append(node.Content, dao.ScalarNode("url"), dao.ScalarNode(diff.Disk))
+			}
+		case DiffURL:
+			node := getProjectNode(diff.Project)
+			node.Content = append(node.Content, dao.ScalarNode("url"), dao.ScalarNode(diff.Disk))
+		case DiffRemoteURL, DiffUndeclaredRemote:
+			node := getChildNode(getProjectNode(diff.Project), "remotes", yaml.MappingNode)
+			node.Content = append(node.Content, dao.ScalarNode(diff.Name), dao.ScalarNode(diff.Disk))
+		case DiffBranch:
+			// Worktree branches are handled together with the worktree list
+			if diff.Name == "" {
+				node := getProjectNode(diff.Project)
+				node.Content = append(node.Content, dao.ScalarNode("branch"), dao.ScalarNode(diff.Disk))
+			}
+		}
+	}
+
+	// Worktrees is a list, so the complete list is written out
+	for _, project := range config.ProjectList {
+		var undeclared []ProjectDiff
+		changed := false
+		for _, diff := range diffs {
+			if diff.Project != project.Name {
+				continue
+			}
+			if diff.Kind == DiffUndeclaredWorktree {
+				undeclared = append(undeclared, diff)
+				changed = true
+			}
+			if diff.Kind == DiffBranch && diff.Name != "" {
+				changed = true
+			}
+		}
+
+		if !changed {
+			continue
+		}
+
+		node := getChildNode(getProjectNode(project.Name), "worktrees", yaml.SequenceNode)
+		for _, wt := range project.WorktreeList {
+			branch := wt.Branch
+			for _, diff := range diffs {
+				if diff.Project == project.Name && diff.Kind == DiffBranch && diff.Name == wt.Path {
+					branch = diff.Disk
+				}
+			}
+			node.Content = append(node.Content, dao.WorktreeNode(dao.Worktree{Path: wt.Path, Branch: branch}))
+		}
+		for _, diff := range undeclared {
+			node.Content = append(node.Content, dao.WorktreeNode(dao.Worktree{Path: diff.Name, Branch: diff.Disk}))
+		}
+	}
+
+	if len(projectsNode.Content) == 0 {
+		return "", nil
+	}
+
+	doc := &yaml.Node{
+		Kind:    yaml.MappingNode,
+		Content: []*yaml.Node{dao.ScalarNode("projects"), projectsNode},
+	}
+
+	return dao.EncodeYAML(doc)
+}
+
+func PrintProjectDiffs(diffs []ProjectDiff) {
+	if len(diffs) == 0 {
+		fmt.Println("Config and disk are in sync")
+		return
+	}
+
+	theme := dao.Theme{
+		Color: core.Ptr(true),
+		Table: dao.DefaultTable,
+	}
+	theme.Table.Border.Rows = core.Ptr(false)
+	theme.Table.Header.Format = core.Ptr("t")
+
+	options := print.PrintTableOptions{
+		Theme:            theme,
+		Output:           "table",
+		Color:            *theme.Color,
+		AutoWrap:         true,
+		OmitEmptyRows:    false,
+		OmitEmptyColumns: false,
+	}
+
+	rows := make([]dao.Row, len(diffs))
+	for i, diff := range diffs {
+		rows[i] = dao.Row{Columns: []string{
+			diff.Project,
+			diff.GetValue("difference", 0),
+			color.FgGreen.Sprint(diff.Config),
+			color.FgRed.Sprint(diff.Disk),
+		}}
+	}
+
+	fmt.Println()
+	print.PrintTable(rows, options, []string{"project", "difference", "config", "disk"}, []string{}, os.Stdout)
+	fmt.Println()
+}
@@ -0,0 +1,135 @@
+package exec
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDiff_Projects(t *testing.T) {
+	gitEnv(t)
+	dir := t.TempDir()
+
+	initRepo(t, filepath.Join(dir, "api"), "git@example.com:api.git")
+	initRepo(t, filepath.Join(dir, "web"), "git@example.com:web-old.git")
+	testGit(t, filepath.Join(dir, "web"), "remote", "add", "upstream", "git@example.com:upstream.git")
+	initRepo(t, filepath.Join(dir, "tools"), "git@example.com:tools.git")
+	err := os.MkdirAll(filepath.Join(dir, "docs"), 0o755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := readTestConfig(t, dir, `
+projects:
+  api:
+    url: git@example.com:api.git
+    branch: main
+  web:
+    url: git@example.com:web.git
+    branch: develop
+  docs:
+  lib:
+    url: git@example.com:lib.git
+`)
+
+	diffs, err := DiffProjects(&config, config.ProjectList, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// api is in sync and docs is not a git repository, neither has differences
+	expected := []ProjectDiff{
+		{Project: "web", Kind: DiffURL, Config: "git@example.com:web.git", Disk: "git@example.com:web-old.git"},
+		{Project: "web", Kind: DiffUndeclaredRemote, Name: "upstream", Disk: "git@example.com:upstream.git"},
+		{Project: "web", Kind: DiffBranch, Config: "develop", Disk: "main"},
+		{Project: "lib", Kind: DiffMissingClone, Config: "lib"},
+		{Project: "tools", Kind: DiffUndeclaredRepo, Name: "tools", Disk: "git@example.com:tools.git"},
+	}
+	if !reflect.DeepEqual(diffs, expected) {
+		t.Errorf("expected diffs:\n%+v\ngot:\n%+v", expected, diffs)
+	}
+
+	patch, err := DiffPatch(&config, diffs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedPatch := `projects:
+  web:
+    url: git@example.com:web-old.git
+    remotes:
+      upstream: git@example.com:upstream.git
+    branch: main
+  tools:
+    path: tools
+    url: git@example.com:tools.git
+`
+	if patch != expectedPatch {
+		t.Errorf("expected patch:\n%s\ngot:\n%s", expectedPatch, patch)
+	}
+
+	// Projects in sync have nothing to patch
+	diffs, err = DiffProjects(&config, config.ProjectList[:1], false)
+	if err != nil || len(diffs) != 0 {
+		t.Errorf("expected api to be in sync, got %+v, %v", diffs, err)
+	}
+	patch, err = DiffPatch(&config, diffs)
+	if err != nil || patch != "" {
+		t.Errorf("expected empty patch, got %q, %v", patch, err)
+	}
+}
+
+func TestDiff_Worktrees(t *testing.T) {
+	gitEnv(t)
+	dir := t.TempDir()
+
+	api := filepath.Join(dir, "api")
+	initRepo(t, api, "git@example.com:api.git")
+	testGit(t, api, "worktree", "add", "--quiet", "-b", "feature", filepath.Join(api, "wt", "feature"))
+	testGit(t, api, "worktree", "add", "--quiet", "-b", "hotfix", filepath.Join(api, "wt", "hotfix"))
+
+	config := readTestConfig(t, dir, `
+projects:
+  api:
+    url: git@example.com:api.git
+    worktrees:
+      - path: wt/feature
+        branch: feature-old
+      - path: wt/missing
+        branch: missing
+`)
+
+	diffs, err := DiffProjects(&config, config.ProjectList, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []ProjectDiff{
+		{Project: "api", Kind: DiffBranch, Name: "wt/feature", Config: "feature-old", Disk: "feature"},
+		{Project: "api", Kind: DiffMissingWorktree, Name: "wt/missing", Config: "missing"},
+		{Project: "api", Kind: DiffUndeclaredWorktree, Name: "wt/hotfix", Disk: "hotfix"},
+	}
+	if !reflect.DeepEqual(diffs, expected) {
+		t.Errorf("expected diffs:\n%+v\ngot:\n%+v", expected, diffs)
+	}
+
+	// The worktree list is written out complete, missing worktrees are kept
+	// since mani sync creates them
+	patch, err := DiffPatch(&config, diffs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedPatch := `projects:
+  api:
+    worktrees:
+      - path: wt/feature
+        branch: feature
+      - path: wt/missing
+        branch: missing
+      - path: wt/hotfix
+        branch: hotfix
+`
+	if patch != expectedPatch {
+		t.Errorf("expected patch:\n%s\ngot:\n%s", expectedPatch, patch)
+	}
+}
@@ -4,9 +4,11 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"slices"
 	"strings"
 
 	"github.com/gookit/color"
+	"gopkg.in/yaml.v3"
 
 	"github.com/alajmo/mani/core"
 	"github.com/alajmo/mani/core/dao"
@@ -18,6 +20,7 @@ type Exec struct {
 	Projects []dao.Project
 	Tasks    []dao.Task
 	Config   dao.Config
+	Secrets  []string
 }
 
 type TableCmd struct {
@@ -48,6 +51,11 @@ func (exec *Exec) Run(
 		return err
 	}
 
+	err = exec.ResolveSecrets(runFlags.DryRun)
+	if err != nil {
+		return err
+	}
+
 	clientCh := make(chan Client, len(projects))
 	errCh := make(chan error, len(projects))
 	err = exec.SetClients(clientCh, errCh)
@@ -98,6 +106,11 @@ func (exec *Exec) RunTUI(
 		return err
 	}
 
+	err = exec.ResolveSecrets(runFlags.DryRun)
+	if err != nil {
+		return err
+	}
+
 	tasks := exec.Tasks
 
 	clientCh := make(chan Client, len(projects))
@@ -136,6 +149,12 @@ func (exec *Exec) SetClients(
 	ignoreNonExisting := exec.Tasks[0].SpecData.IgnoreNonExisting
 	projects := exec.Projects
 
+	// Only the env of the projects the task runs in is evaluated
+	err := dao.ParseProjectsEnv(projects)
+	if err != nil {
+		return err
+	}
+
 	var clients []Client
 	for i, project := range projects {
 		func(i int, project dao.Project) {
@@ -149,7 +168,7 @@ func (exec *Exec) SetClients(
 				return
 			}
 
-			client := Client{Path: projectPath, Name: project.Name, Env: project.EnvList}
+			client := Client{Path: projectPath, Name: project.Name, Env: project.EnvList, Secrets: exec.Secrets}
 			clientCh <- client
 
 			clients = append(clients, client)
@@ -169,6 +188,27 @@ func (exec *Exec) SetClients(
 	return nil
 }
 
+// ResolveSecrets resolves the secrets the tasks use, unless it's a dry run
+// which doesn't run any commands
+func (exec *Exec) ResolveSecrets(dryRun bool) error {
+	if dryRun {
+		return nil
+	}
+
+	var names []string
+	for _, task := range exec.Tasks {
+		names = append(names, task.SecretNames(exec.Config.SecretList)...)
+	}
+
+	secrets, err := exec.Config.ResolveSecrets(names)
+	if err != nil {
+		return err
+	}
+	exec.Secrets = secrets
+
+	return nil
+}
+
 // ParseTask processes and updates task configurations based on runtime flags and user arguments.
 // It handles theme, specification, environment variables, and execution settings for each task.
 //
@@ -180,15 +220,35 @@ func (exec *Exec) SetClients(
 // 5. Processes environment variables for the task and its commands
 //
 // Environment variable processing order:
-// 1. Configuration level variables
-// 2. Task level variables
-// 3. Command level variables
-// 4. User provided arguments
+// 1. Configuration level env files
+// 2. Configuration level variables
+// 3. Task level env files
+// 4. Task level variables
+// 5. Command level variables
+// 6. User provided arguments
 func (exec *Exec) ParseTask(userArgs []string, runFlags *core.RunFlags, setRunFlags *core.SetRunFlags) error {
-	configEnv, err := dao.EvaluateEnv(exec.Config.EnvList)
+	configEnv, err := dao.EvaluateEnv(exec.Config.EnvList, exec.Config.EnvCache)
 	if err != nil {
 		return err
 	}
+	configEnv = dao.MergeEnvs(configEnv, dao.EnvFilesEnv(exec.Config.EnvFileList))
+
+	// The task runs once per project, evaluate the same env only once
+	evaluated := map[string][]string{}
+	parseEnv := func(env yaml.Node, parentEnv []string, configEnv []string) ([]string, error) {
+		key := strings.Join(slices.Concat(dao.ParseNodeEnv(env), []string{"\x00"}, parentEnv, []string{"\x00"}, configEnv), "\n")
+		if envs, ok := evaluated[key]; ok {
+			return envs, nil
+		}
+
+		envs, err := dao.ParseTaskEnv(env, userArgs, parentEnv, configEnv)
+		if err != nil {
+			return nil, err
+		}
+		evaluated[key] = envs
+
+		return envs, nil
+	}
 
 	for i := range exec.Tasks {
 		// Update theme property if user flag is provided
@@ -248,7 +308,9 @@ func (exec *Exec) ParseTask(userArgs []string, runFlags *core.RunFlags, setRunFl
 
 		// Parse env here instead of config since we're only interested in tasks run, and not all tasks.
 		// Also, userArgs is not present in the config.
-		envs, err := dao.ParseTaskEnv(exec.Tasks[i].Env, userArgs, []string{}, configEnv)
+		// Task env files take precedence over the config env
+		taskConfigEnv := dao.MergeEnvs(dao.EnvFilesEnv(exec.Tasks[i].EnvFileList), configEnv)
+		envs, err := parseEnv(exec.Tasks[i].Env, []string{}, taskConfigEnv)
 		if err != nil {
 			return err
 		}
@@ -256,7 +318,7 @@ func (exec *Exec) ParseTask(userArgs []string, runFlags *core.RunFlags, setRunFl
 
 		// Set environment variables for sub-commands
 		for j := range exec.Tasks[i].Commands {
-			envs, err := dao.ParseTaskEnv(exec.Tasks[i].Commands[j].Env, userArgs, exec.Tasks[i].EnvList, configEnv)
+			envs, err := parseEnv(exec.Tasks[i].Commands[j].Env, exec.Tasks[i].EnvList, taskConfigEnv)
 			if err != nil {
 				return err
 			}
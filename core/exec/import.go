@@ -0,0 +1,125 @@
+package exec
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/gookit/color"
+
+	"github.com/alajmo/mani/core"
+	"github.com/alajmo/mani/core/dao"
+	"github.com/alajmo/mani/core/print"
+)
+
+const (
+	ImportUpdated = "updated"
+	ImportCached  = "cached"
+	ImportFailed  = "failed"
+)
+
+type ImportStatus struct {
+	Source  string
+	Status  string
+	Message string
+}
+
+func (i ImportStatus) GetValue(key string, _ int) string {
+	switch key {
+	case "import":
+		return i.Source
+	case "status":
+		switch i.Status {
+		case ImportUpdated:
+			return color.FgGreen.Sprint(i.Status)
+		case ImportCached:
+			return color.FgYellow.Sprint(i.Status)
+		default:
+			return color.FgRed.Sprint(i.Status)
+		}
+	case "message":
+		return i.Message
+	default:
+		return ""
+	}
+}
+
+// UpdateImports fetches remote imports into the cache. If fetching fails, the
+// cached copy is kept. Imports in done are skipped, and updated imports are
+// added to done.
+func UpdateImports(imports []dao.Import, done map[string]bool) []ImportStatus {
+	var statuses []ImportStatus
+
+	for _, imp := range imports {
+		if !dao.IsRemoteImport(imp.Path) {
+			continue
+		}
+
+		status := ImportStatus{Source: imp.Path}
+
+		r, err := dao.ParseRemoteImport(imp.Path)
+		if err != nil {
+			if !done[imp.Path] {
+				done[imp.Path] = true
+				status.Status = ImportFailed
+				status.Message = err.Error()
+				statuses = append(statuses, status)
+			}
+			continue
+		}
+
+		// Imports of different files in the same repository share the cache
+		cacheDir, err := r.CacheDir()
+		if err != nil {
+			cacheDir = imp.Path
+		}
+		if done[cacheDir] {
+			continue
+		}
+		done[cacheDir] = true
+
+		cached := r.IsCached()
+		err = r.Fetch()
+		switch {
+		case err == nil:
+			status.Status = ImportUpdated
+			status.Message = cacheDir
+		case cached:
+			status.Status = ImportCached
+			status.Message = fmt.Sprintf("using cached copy, %s", err)
+		default:
+			status.Status = ImportFailed
+			status.Message = err.Error()
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	return statuses
+}
+
+func PrintImportStatus(statuses []ImportStatus) {
+	if len(statuses) == 0 {
+		fmt.Println("No remote imports")
+		return
+	}
+
+	theme := dao.Theme{
+		Table: dao.DefaultTable,
+		Color: core.Ptr(true),
+	}
+	theme.Table.Border.Rows = core.Ptr(false)
+	theme.Table.Header.Format = core.Ptr("t")
+
+	options := print.PrintTableOptions{
+		Theme:            theme,
+		Output:           "table",
+		Color:            *theme.Color,
+		AutoWrap:         true,
+		OmitEmptyRows:    false,
+		OmitEmptyColumns: false,
+	}
+
+	fmt.Println()
+	print.PrintTable(statuses, options, []string{"import", "status", "message"}, []string{}, os.Stdout)
+	fmt.Println()
+}
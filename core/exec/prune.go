@@ -0,0 +1,207 @@
+package exec
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gookit/color"
+
+	"github.com/alajmo/mani/core"
+	"github.com/alajmo/mani/core/dao"
+	"github.com/alajmo/mani/core/print"
+)
+
+type orphanState struct {
+	project  dao.Project
+	dirty    bool
+	unpushed bool
+	err      error
+}
+
+func (o orphanState) safe() bool {
+	return o.err == nil && !o.dirty && !o.unpushed
+}
+
+func (o orphanState) status() string {
+	switch {
+	case o.err != nil:
+		return color.FgRed.Sprintf("error: %s", o.err)
+	case o.dirty && o.unpushed:
+		return color.FgYellow.Sprint("uncommitted changes, unpushed commits")
+	case o.dirty:
+		return color.FgYellow.Sprint("uncommitted changes")
+	case o.unpushed:
+		return color.FgYellow.Sprint("unpushed commits")
+	default:
+		return color.FgGreen.Sprint("clean")
+	}
+}
+
+// PruneProjects finds git repositories below the config directory which are no
+// longer declared in the config and removes them, or moves them to archiveDir if set.
+// Repositories with uncommitted changes or unpushed commits are never touched.
+// When dryRun is set, the orphaned repositories are only listed. Otherwise they're
+// listed and pruned after the user confirms, unless yes is set.
+func PruneProjects(config *dao.Config, archiveDir string, dryRun bool, yes bool) error {
+	var excludeDirs []string
+	if archiveDir != "" {
+		var err error
+		archiveDir, err = core.GetAbsolutePath(config.Dir, archiveDir, "")
+		if err != nil {
+			return err
+		}
+		excludeDirs = append(excludeDirs, archiveDir)
+	}
+
+	orphans, err := config.GetOrphanedProjects(excludeDirs)
+	if err != nil {
+		return err
+	}
+
+	if len(orphans) == 0 {
+		fmt.Println("\nNo orphaned projects found")
+		return nil
+	}
+
+	states := make([]orphanState, len(orphans))
+	numSafe := 0
+	for i, orphan := range orphans {
+		states[i].project = orphan
+		states[i].dirty, err = core.IsGitDirty(orphan.Path)
+		if err != nil {
+			states[i].err = err
+			continue
+		}
+		states[i].unpushed, err = core.HasUnpushedCommits(orphan.Path)
+		if err != nil {
+			states[i].err = err
+			continue
+		}
+		if states[i].safe() {
+			numSafe++
+		}
+	}
+
+	action := "remove"
+	if archiveDir != "" {
+		action = "archive"
+	}
+
+	planned := func(state orphanState) string {
+		if !state.safe() {
+			return color.FgRed.Sprint("skip")
+		}
+		return action
+	}
+
+	if dryRun {
+		printOrphans("Following orphaned projects would be pruned (dry run)", states, planned)
+		return nil
+	}
+
+	if !yes {
+		printOrphans("Following orphaned projects will be pruned", states, planned)
+		question := fmt.Sprintf("Remove %d projects? [y/N] ", numSafe)
+		if archiveDir != "" {
+			question = fmt.Sprintf("Move %d projects to %s? [y/N] ", numSafe, archiveDir)
+		}
+
+		if numSafe == 0 || !confirm(question) {
+			fmt.Println("No projects pruned")
+			return nil
+		}
+	}
+
+	printOrphans("Pruned orphaned projects", states, func(state orphanState) string {
+		if !state.safe() {
+			return color.FgRed.Sprint("skip")
+		}
+
+		err := pruneProject(state.project, config.Dir, archiveDir)
+		switch {
+		case err != nil:
+			return color.FgRed.Sprintf("failed: %s", err)
+		case archiveDir != "":
+			return color.FgGreen.Sprint("archived")
+		default:
+			return color.FgGreen.Sprint("removed")
+		}
+	})
+
+	return nil
+}
+
+// printOrphans prints a table of the orphaned projects, with the result of
+// calling action on each of them
+func printOrphans(title string, states []orphanState, action func(orphanState) string) {
+	data := dao.TableOutput{
+		Headers: []string{"path", "status", "action"},
+		Rows:    []dao.Row{},
+	}
+
+	for _, state := range states {
+		data.Rows = append(data.Rows, dao.Row{Columns: []string{state.project.RelPath, state.status(), action(state)}})
+	}
+
+	theme := dao.Theme{
+		Color: core.Ptr(true),
+		Table: dao.DefaultTable,
+	}
+	theme.Table.Border.Rows = core.Ptr(false)
+	theme.Table.Header.Format = core.Ptr("t")
+
+	options := print.PrintTableOptions{
+		Theme:            theme,
+		Output:           "table",
+		Color:            *theme.Color,
+		AutoWrap:         true,
+		OmitEmptyRows:    false,
+		OmitEmptyColumns: false,
+	}
+
+	fmt.Println()
+	fmt.Println(title)
+	fmt.Println()
+	print.PrintTable(data.Rows, options, data.Headers, []string{}, os.Stdout)
+}
+
+// confirm asks the user the question and returns true if the answer is yes
+func confirm(question string) bool {
+	fmt.Println()
+	fmt.Print(question)
+
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && answer == "" {
+		fmt.Println()
+		return false
+	}
+
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+func pruneProject(project dao.Project, configDir string, archiveDir string) error {
+	if archiveDir == "" {
+		return os.RemoveAll(project.Path)
+	}
+
+	relPath, err := filepath.Rel(configDir, project.Path)
+	if err != nil {
+		return err
+	}
+
+	dest := filepath.Join(archiveDir, relPath)
+	if _, err := os.Stat(dest); err == nil {
+		return &core.PathAlreadyExists{Path: dest}
+	}
+
+	err = os.MkdirAll(filepath.Dir(dest), os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(project.Path, dest)
+}
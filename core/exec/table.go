@@ -51,21 +51,19 @@ func (exec *Exec) Table(runFlags *core.RunFlags) dao.TableOutput {
 	**/
 	data.Headers = append(data.Headers, "project")
 
-	// Append Command names if set
-	for _, subTask := range task.Commands {
-		if subTask.Name != "" {
-			data.Headers = append(data.Headers, subTask.Name)
-		} else {
-			data.Headers = append(data.Headers, "output")
-		}
+	// Project task overrides can run more commands than the task, each
+	// command gets a column, named after the first task running a command in it
+	numColumns := 0
+	for _, t := range exec.Tasks {
+		numColumns = max(numColumns, numTaskColumns(t))
 	}
 
-	// Append Command name if set
-	if task.Cmd != "" {
-		if task.Name != "" {
-			data.Headers = append(data.Headers, task.Name)
-		} else {
-			data.Headers = append(data.Headers, "output")
+	for k := range numColumns {
+		for _, t := range exec.Tasks {
+			if k < numTaskColumns(t) {
+				data.Headers = append(data.Headers, taskColumnName(t, k))
+				break
+			}
 		}
 	}
 
@@ -73,11 +71,7 @@ func (exec *Exec) Table(runFlags *core.RunFlags) dao.TableOutput {
 	for i, p := range projects {
 		data.Rows = append(data.Rows, dao.Row{Columns: []string{p.Name}})
 
-		for range task.Commands {
-			data.Rows[i].Columns = append(data.Rows[i].Columns, "")
-		}
-
-		if task.Cmd != "" {
+		for range numColumns {
 			data.Rows[i].Columns = append(data.Rows[i].Columns, "")
 		}
 	}
@@ -127,7 +121,7 @@ func (exec *Exec) TableWork(rIndex int, dryRun bool, data dao.TableOutput, dataM
 		}
 
 		if cmd.TTY {
-			return ExecTTY(cmd.Cmd, cmd.EnvList)
+			return ExecTTY(cmd.Cmd, dao.MergeEnvs(cmd.EnvList, client.Secrets))
 		}
 
 		err := RunTableCmd(args, data, dataMutex, &wg)
@@ -149,7 +143,7 @@ func (exec *Exec) TableWork(rIndex int, dryRun bool, data dao.TableOutput, dataM
 		}
 
 		if task.TTY {
-			return ExecTTY(task.Cmd, task.EnvList)
+			return ExecTTY(task.Cmd, dao.MergeEnvs(task.EnvList, client.Secrets))
 		}
 
 		err := RunTableCmd(args, data, dataMutex, &wg)
@@ -163,6 +157,30 @@ func (exec *Exec) TableWork(rIndex int, dryRun bool, data dao.TableOutput, dataM
 	return nil
 }
 
+// numTaskColumns returns the number of output columns of the task, one per
+// command and one for cmd
+func numTaskColumns(task dao.Task) int {
+	if task.Cmd != "" {
+		return len(task.Commands) + 1
+	}
+
+	return len(task.Commands)
+}
+
+// taskColumnName returns the header of the task's k-th output column
+func taskColumnName(task dao.Task, k int) string {
+	name := task.Name
+	if k < len(task.Commands) {
+		name = task.Commands[k].Name
+	}
+
+	if name == "" {
+		return "output"
+	}
+
+	return name
+}
+
 func RunTableCmd(t TableCmd, data dao.TableOutput, dataMutex *sync.RWMutex, wg *sync.WaitGroup) error {
 	combinedEnvs := dao.MergeEnvs(t.client.Env, t.env)
 
@@ -85,7 +85,7 @@ func (exec *Exec) TextWork(
 		}
 
 		if cmd.TTY {
-			return ExecTTY(cmd.Cmd, cmd.EnvList)
+			return ExecTTY(cmd.Cmd, dao.MergeEnvs(cmd.EnvList, client.Secrets))
 		}
 
 		err := RunTextCmd(args, task.ThemeData.Stream, prefix, task.SpecData.Parallel, &wg, stdout, stderr)
@@ -110,7 +110,7 @@ func (exec *Exec) TextWork(
 		}
 
 		if task.TTY {
-			return ExecTTY(task.Cmd, task.EnvList)
+			return ExecTTY(task.Cmd, dao.MergeEnvs(task.EnvList, client.Secrets))
 		}
 
 		err := RunTextCmd(args, task.ThemeData.Stream, prefix, task.SpecData.Parallel, &wg, stdout, stderr)
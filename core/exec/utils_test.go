@@ -0,0 +1,70 @@
+package exec
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alajmo/mani/core/dao"
+)
+
+// Helper functions
+
+// gitEnv isolates git from the user's git config
+func gitEnv(t *testing.T) {
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_AUTHOR_NAME", "mani")
+	t.Setenv("GIT_AUTHOR_EMAIL", "mani@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "mani")
+	t.Setenv("GIT_COMMITTER_EMAIL", "mani@example.com")
+}
+
+func testGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+
+	return strings.TrimSpace(string(out))
+}
+
+// initRepo creates a git repository on branch main with one commit, and an
+// origin remote if url is set
+func initRepo(t *testing.T, dir string, url string) {
+	t.Helper()
+
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testGit(t, dir, "init", "--quiet", "--initial-branch", "main")
+	testGit(t, dir, "commit", "--quiet", "--allow-empty", "-m", "init")
+	if url != "" {
+		testGit(t, dir, "remote", "add", "origin", url)
+	}
+}
+
+func readTestConfig(t *testing.T, dir string, content string) dao.Config {
+	t.Helper()
+
+	path := filepath.Join(dir, "mani.yaml")
+	err := os.WriteFile(path, []byte(content), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := dao.ReadConfig(path, "", "", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	return config
+}
@@ -0,0 +1,210 @@
+package exec
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/gookit/color"
+
+	"github.com/alajmo/mani/core"
+	"github.com/alajmo/mani/core/dao"
+	"github.com/alajmo/mani/core/print"
+)
+
+type WorktreeStatus struct {
+	Project  string
+	Path     string
+	Branch   string
+	Declared bool
+	Exists   bool
+	Dirty    bool
+}
+
+func (w WorktreeStatus) GetValue(key string, _ int) string {
+	switch key {
+	case "project":
+		return w.Project
+	case "path":
+		return w.Path
+	case "branch":
+		return w.Branch
+	case "config":
+		if w.Declared {
+			return color.FgGreen.Sprintf("✓")
+		}
+		return color.FgRed.Sprintf("✕")
+	case "status":
+		switch {
+		case !w.Exists:
+			return color.FgRed.Sprint("missing")
+		case w.Dirty:
+			return color.FgYellow.Sprint("dirty")
+		default:
+			return color.FgGreen.Sprint("clean")
+		}
+	default:
+		return ""
+	}
+}
+
+func worktreeAbsPath(project dao.Project, path string) string {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+
+	return filepath.Join(project.Path, path)
+}
+
+// AddWorktree creates a git worktree for the project and adds it to the config.
+// An existing branch is checked out, otherwise a new branch is created.
+func AddWorktree(config *dao.Config, project dao.Project, wt dao.Worktree) error {
+	if _, err := os.Stat(project.Path); os.IsNotExist(err) {
+		return &core.PathDoesNotExist{Path: project.Path}
+	}
+
+	wtPath := worktreeAbsPath(project, wt.Path)
+	for _, existing := range project.WorktreeList {
+		if worktreeAbsPath(project, existing.Path) == wtPath {
+			return &core.WorktreeAlreadyExists{Project: project.Name, Path: wt.Path}
+		}
+	}
+
+	if wt.Branch == "" {
+		wt.Branch = filepath.Base(wt.Path)
+	}
+
+	if _, err := os.Stat(wtPath); os.IsNotExist(err) {
+		err = CreateWorktree(project.Path, wtPath, wt.Branch, false)
+
+		// Only a missing branch is created, other errors are returned as is
+		if err != nil && !localBranchExists(project.Path, wt.Branch) && !remoteBranchExists(project.Path, wt.Branch) {
+			err = CreateWorktree(project.Path, wtPath, wt.Branch, true)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return config.AddProjectWorktree(project.Name, wt)
+}
+
+// DeleteWorktree removes a git worktree from disk and from the config
+func DeleteWorktree(config *dao.Config, project dao.Project, path string, force bool) error {
+	wtPath := worktreeAbsPath(project, path)
+
+	declared := false
+	for _, wt := range project.WorktreeList {
+		if worktreeAbsPath(project, wt.Path) == wtPath {
+			declared = true
+			path = wt.Path
+			break
+		}
+	}
+
+	_, err := os.Stat(wtPath)
+	exists := err == nil
+	if !declared && !exists {
+		return &core.WorktreeNotFound{Project: project.Name, Path: path}
+	}
+
+	if exists {
+		err := RemoveWorktree(project.Path, wtPath, force)
+		if err != nil {
+			return err
+		}
+	}
+
+	if declared {
+		return config.RemoveProjectWorktree(project.Name, path)
+	}
+
+	return nil
+}
+
+// ListWorktrees returns the worktrees declared in the config and the worktrees
+// found on disk for each project.
+func ListWorktrees(projects []dao.Project) ([]WorktreeStatus, error) {
+	var statuses []WorktreeStatus
+
+	for _, project := range projects {
+		found := map[string]string{}
+		if _, err := os.Stat(filepath.Join(project.Path, ".git")); err == nil {
+			var err error
+			found, err = GetWorktrees(project.Path)
+			if err != nil {
+				return statuses, err
+			}
+		}
+
+		declared := make(map[string]bool)
+		for _, wt := range project.WorktreeList {
+			wtPath := worktreeAbsPath(project, wt.Path)
+			declared[wtPath] = true
+
+			status := WorktreeStatus{Project: project.Name, Path: wt.Path, Branch: wt.Branch, Declared: true}
+			if branch, exists := found[wtPath]; exists {
+				status.Exists = true
+				status.Branch = branch
+				dirty, err := core.IsGitDirty(wtPath)
+				if err != nil {
+					return statuses, err
+				}
+				status.Dirty = dirty
+			}
+
+			statuses = append(statuses, status)
+		}
+
+		paths := make([]string, 0, len(found))
+		for wtPath := range found {
+			paths = append(paths, wtPath)
+		}
+		sort.Strings(paths)
+
+		for _, wtPath := range paths {
+			if declared[wtPath] {
+				continue
+			}
+
+			relPath, err := filepath.Rel(project.Path, wtPath)
+			if err != nil {
+				relPath = wtPath
+			}
+
+			dirty, err := core.IsGitDirty(wtPath)
+			if err != nil {
+				return statuses, err
+			}
+
+			statuses = append(statuses, WorktreeStatus{
+				Project: project.Name,
+				Path:    relPath,
+				Branch:  found[wtPath],
+				Exists:  true,
+				Dirty:   dirty,
+			})
+		}
+	}
+
+	return statuses, nil
+}
+
+func PrintWorktrees(statuses []WorktreeStatus, theme dao.Theme, output string) {
+	theme.Table.Border.Rows = core.Ptr(false)
+	theme.Table.Header.Format = core.Ptr("t")
+
+	options := print.PrintTableOptions{
+		Theme:            theme,
+		Output:           output,
+		Color:            *theme.Color,
+		AutoWrap:         true,
+		OmitEmptyRows:    false,
+		OmitEmptyColumns: false,
+	}
+
+	fmt.Println()
+	print.PrintTable(statuses, options, []string{"project", "path", "branch", "status", "config"}, []string{}, os.Stdout)
+	fmt.Println()
+}
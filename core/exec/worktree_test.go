@@ -0,0 +1,52 @@
+package exec
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alajmo/mani/core/dao"
+)
+
+func TestWorktree_Add(t *testing.T) {
+	gitEnv(t)
+	dir := t.TempDir()
+
+	api := filepath.Join(dir, "api")
+	initRepo(t, api, "")
+	testGit(t, api, "branch", "existing")
+
+	config := readTestConfig(t, dir, "projects:\n  api:\n")
+	project := config.ProjectList[0]
+
+	// Existing branches are checked out, missing branches are created
+	for _, wt := range []dao.Worktree{{Path: "wt/existing"}, {Path: "wt/new", Branch: "new"}} {
+		err := AddWorktree(&config, project, wt)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	worktrees, err := GetWorktrees(api)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for path, branch := range map[string]string{"wt/existing": "existing", "wt/new": "new"} {
+		if worktrees[filepath.Join(api, path)] != branch {
+			t.Errorf("expected worktree %s on branch %s, got %v", path, branch, worktrees)
+		}
+	}
+
+	dat, _ := os.ReadFile(filepath.Join(dir, "mani.yaml"))
+	if !strings.Contains(string(dat), "path: wt/existing") || !strings.Contains(string(dat), "path: wt/new") {
+		t.Errorf("expected worktrees to be added to the config, got:\n%s", dat)
+	}
+
+	// Errors other than a missing branch are returned, without creating the
+	// branch again
+	err = AddWorktree(&config, project, dao.Worktree{Path: "wt/main", Branch: "main"})
+	if err == nil || !strings.Contains(err.Error(), "already") || strings.Contains(err.Error(), "already exists") {
+		t.Errorf("expected checked out branch error, got %v", err)
+	}
+}
@@ -91,6 +91,10 @@ type SyncFlags struct {
 	Status                  bool
 	SyncRemotes             bool
 	RemoveOrphanedWorktrees bool
+	Prune                   bool
+	PruneArchive            string
+	DryRun                  bool
+	Yes                     bool
 	Forks                   uint32
 }
 
@@ -105,4 +109,40 @@ type SetSyncFlags struct {
 type InitFlags struct {
 	AutoDiscovery bool
 	SyncGitignore bool
+	Update        bool
+}
+
+type DiffFlags struct {
+	Patch bool
+}
+
+type AddProjectFlags struct {
+	Path   string
+	URL    string
+	Desc   string
+	Branch string
+	Tags   []string
+}
+
+type AddTaskFlags struct {
+	Desc string
+}
+
+type WorktreeFlags struct {
+	Branch string
+	Force  bool
+}
+
+type BranchFlags struct {
+	Push   bool
+	Force  bool
+	Remote string
+}
+
+type ImportManifestFlags struct {
+	Format string
+}
+
+type ExportFlags struct {
+	Format string
 }
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/alajmo/mani/core/dao"
 )
@@ -35,8 +36,16 @@ func PrintProjectBlocks(projects []dao.Project, colorize bool, block dao.Block,
 			output += printKeyValue(false, "", "path", ":", project.RelPath, *block.Key, *block.Value)
 		}
 
+		if len(project.Extends) > 0 {
+			output += printKeyValue(false, "", "extends", ":", strings.Join(project.Extends, ", "), *block.Key, *block.Value)
+		}
+
 		output += printKeyValue(false, "", "url", ":", project.URL, *block.Key, *block.Value)
 
+		if project.Clone != "" {
+			output += printKeyValue(false, "", "clone", ":", project.Clone, *block.Key, *block.Value)
+		}
+
 		if len(project.RemoteList) > 0 {
 			output += printKeyValue(false, "", "remotes", ":", "", *block.Key, *block.Value)
 			for _, remote := range project.RemoteList {
@@ -66,6 +75,18 @@ func PrintProjectBlocks(projects []dao.Project, colorize bool, block dao.Block,
 			output += printEnv(project.EnvList, block)
 		}
 
+		if len(project.EnvFileList) > 0 {
+			output += printEnvFiles(project.EnvFileList, block)
+		}
+
+		if len(project.TaskOverrides) > 0 {
+			var names []string
+			for _, task := range project.TaskOverrides {
+				names = append(names, task.Name)
+			}
+			output += printKeyValue(false, "", "tasks", ":", strings.Join(names, ", "), *block.Key, *block.Value)
+		}
+
 		if i < len(projects)-1 {
 			output += "\n--\n\n"
 		}
@@ -90,6 +111,9 @@ func PrintTaskBlock(tasks []dao.Task, colorize bool, block dao.Block, f Formatte
 	for i, task := range tasks {
 		output += printKeyValue(false, "", "name", ":", task.Name, *block.Key, *block.Value)
 		output += printKeyValue(false, "", "description", ":", task.Desc, *block.Key, *block.Value)
+		if task.Extends != "" {
+			output += printKeyValue(false, "", "extends", ":", task.Extends, *block.Key, *block.Value)
+		}
 		output += printKeyValue(false, "", "theme", ":", task.ThemeData.Name, *block.Key, *block.Value)
 		output += printKeyValue(false, "", "target", ":", "", *block.Key, *block.Value)
 		output += printKeyValue(true, "", "all", ":", strconv.FormatBool(task.TargetData.All), *block.Key, trueOrFalse(task.TargetData.All))
@@ -110,6 +134,10 @@ func PrintTaskBlock(tasks []dao.Task, colorize bool, block dao.Block, f Formatte
 			output += printEnv(task.EnvList, block)
 		}
 
+		if len(task.EnvFileList) > 0 {
+			output += printEnvFiles(task.EnvFileList, block)
+		}
+
 		if task.Cmd != "" {
 			output += printKeyValue(false, "", "cmd", ":", "", *block.Key, *block.Value)
 			output += printCmd(task.Cmd)
@@ -130,6 +158,13 @@ func PrintTaskBlock(tasks []dao.Task, colorize bool, block dao.Block, f Formatte
 			}
 		}
 
+		if len(task.Sources) > 0 {
+			output += printKeyValue(false, "", "sources", ":", "", *block.Key, *block.Value)
+			for _, source := range task.Sources {
+				output += printKeyValue(true, "- ", source.Project, ":", source.String(), *block.Key, *block.Value)
+			}
+		}
+
 		if i < len(tasks)-1 {
 			output += "\n--\n\n"
 		}
@@ -187,7 +222,27 @@ func printEnv(env []string, block dao.Block) string {
 
 	for _, env := range env {
 		parts := strings.SplitN(strings.TrimSuffix(env, "\n"), "=", 2)
-		output += printKeyValue(true, "", parts[0], ":", parts[1], *block.Key, *block.Value)
+		value := dao.Redact(parts[1])
+		if cachedAt, ok := dao.EnvCachedAt(env); ok {
+			value = fmt.Sprintf("%s (cached %s ago)", value, time.Since(cachedAt).Round(time.Second))
+		}
+		output += printKeyValue(true, "", parts[0], ":", value, *block.Key, *block.Value)
+	}
+
+	return output
+}
+
+func printEnvFiles(envFiles []dao.EnvFile, block dao.Block) string {
+	output := ""
+
+	output += printKeyValue(false, "", "env_files", ":", "", *block.Key, *block.Value)
+
+	for _, envFile := range envFiles {
+		keys := strings.Join(envFile.Keys(), ", ")
+		if !envFile.Found {
+			keys = "(not found)"
+		}
+		output += printKeyValue(true, "", envFile.Path, ":", keys, *block.Key, *block.Value)
 	}
 
 	return output
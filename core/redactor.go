@@ -0,0 +1,113 @@
+package core
+
+import (
+	"bytes"
+	"io"
+	"slices"
+)
+
+// Redactor implements io.Reader. It replaces the secrets in the output of the
+// underlying reader, even if the reader returns a secret split across reads.
+// Output is passed on as soon as it's read, except for a trailing partial
+// secret, which is held back until the next read shows if it's a secret.
+type Redactor struct {
+	reader      io.Reader
+	secrets     [][]byte // longest first, so the longest match is replaced
+	replacement []byte
+	chunk       []byte
+	buf         []byte // read but not redacted yet
+	unread      []byte // redacted but not returned yet
+	eof         bool
+}
+
+func NewRedactor(r io.Reader, secrets []string, replacement string) *Redactor {
+	redactor := &Redactor{
+		reader:      r,
+		replacement: []byte(replacement),
+		chunk:       make([]byte, 32*1024),
+	}
+
+	for _, secret := range secrets {
+		if secret != "" {
+			redactor.secrets = append(redactor.secrets, []byte(secret))
+		}
+	}
+	slices.SortFunc(redactor.secrets, func(a, b []byte) int { return len(b) - len(a) })
+
+	return redactor
+}
+
+func (r *Redactor) Read(p []byte) (n int, err error) {
+	for len(r.unread) == 0 {
+		if r.eof {
+			if len(r.buf) == 0 {
+				return 0, io.EOF
+			}
+			r.unread = r.redact()
+			continue
+		}
+
+		n, err = r.reader.Read(r.chunk)
+		r.buf = append(r.buf, r.chunk[:n]...)
+		if err == io.EOF {
+			r.eof = true
+		} else if err != nil {
+			return 0, err
+		}
+
+		r.unread = r.redact()
+	}
+
+	n = copy(p, r.unread)
+	r.unread = r.unread[n:]
+
+	return n, nil
+}
+
+// redact returns the read output with the secrets replaced. Until the
+// underlying reader is done, a trailing partial secret is kept in the buffer.
+func (r *Redactor) redact() []byte {
+	var out []byte
+
+	i := 0
+	for i < len(r.buf) {
+		rest := r.buf[i:]
+		if !r.eof && r.isPartial(rest) {
+			break
+		}
+
+		if secret := r.match(rest); secret != nil {
+			out = append(out, r.replacement...)
+			i += len(secret)
+			continue
+		}
+
+		out = append(out, r.buf[i])
+		i++
+	}
+
+	r.buf = slices.Clone(r.buf[i:])
+
+	return out
+}
+
+func (r *Redactor) match(b []byte) []byte {
+	for _, secret := range r.secrets {
+		if bytes.HasPrefix(b, secret) {
+			return secret
+		}
+	}
+
+	return nil
+}
+
+// isPartial returns true if b is the start of a secret
+func (r *Redactor) isPartial(b []byte) bool {
+	for _, secret := range r.secrets {
+		if len(b) < len(secret) && bytes.HasPrefix(secret, b) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -39,7 +39,7 @@ func (app *App) Run() error {
 }
 
 func (app *App) Reload() {
-	config, configErr := dao.ReadConfig(misc.Config.Path, "", true)
+	config, configErr := dao.ReadConfig(misc.Config.Path, "", misc.Config.Profile, true)
 	if configErr != nil {
 		app.App.Stop()
 	}
@@ -63,6 +63,8 @@ func (app *App) setupApp(config *dao.Config, themeName string) {
 	projects := config.ProjectList
 	tasks := config.TaskList
 	dao.ParseTasksEnv(tasks)
+	err = dao.ParseProjectsEnv(projects)
+	core.CheckIfError(err)
 	projectTags := config.GetTags()
 	projectPaths := config.GetProjectPaths()
 
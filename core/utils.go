@@ -82,6 +82,48 @@ func GetWorktreeList(repoPath string) (map[string]string, error) {
 	return worktrees, nil
 }
 
+// GetCurrentBranch returns the checked out branch, or an empty string for a detached HEAD
+func GetCurrentBranch(repoPath string) (string, error) {
+	cmd := exec.Command("git", "symbolic-ref", "--quiet", "--short", "HEAD")
+	cmd.Dir = repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		// Exit code 1 means detached HEAD
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+			return "", nil
+		}
+		return "", err
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
+// IsGitDirty returns true if the repository has uncommitted changes or untracked files
+func IsGitDirty(repoPath string) (bool, error) {
+	cmd := exec.Command("git", "status", "--porcelain")
+	cmd.Dir = repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		return false, err
+	}
+
+	return strings.TrimSpace(string(output)) != "", nil
+}
+
+// HasUnpushedCommits returns true if any local branch has commits that are not
+// present on any remote. A repository without remotes counts as unpushed as soon
+// as it has a single commit.
+func HasUnpushedCommits(repoPath string) (bool, error) {
+	cmd := exec.Command("git", "log", "--branches", "--not", "--remotes", "--oneline", "-n", "1")
+	cmd.Dir = repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		return false, err
+	}
+
+	return strings.TrimSpace(string(output)) != "", nil
+}
+
 func FindFileInParentDirs(path string, files []string) (string, error) {
 	for _, file := range files {
 		pathToFile := filepath.Join(path, file)
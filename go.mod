@@ -10,6 +10,7 @@ require (
 	github.com/jinzhu/copier v0.4.0
 	github.com/kr/pretty v0.2.1
 	github.com/otiai10/copy v1.6.0
+	github.com/pelletier/go-toml/v2 v2.2.4 // parseTOML uses the unstable parser API, check it when upgrading
 	github.com/rivo/tview v0.42.0
 	github.com/spf13/cobra v1.10.2
 	github.com/spf13/pflag v1.0.10